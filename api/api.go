@@ -3,14 +3,19 @@ package api
 import (
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
 	"github.com/BrunoQuaresma/openticket/api/database"
+	"github.com/BrunoQuaresma/openticket/api/mailer"
+	"github.com/BrunoQuaresma/openticket/api/storage"
 )
 
 type Server struct {
@@ -18,6 +23,17 @@ type Server struct {
 	validate   *validator.Validate
 	httpServer *http.Server
 	router     *gin.Engine
+	storage    storage.Storage
+	mailer     *mailer.Mailer
+
+	sessionMaxAge time.Duration
+
+	webhookConfig WebhookConfig
+	events        *eventHub
+
+	stopEmailWorker   func()
+	stopWebhookWorker func()
+	stopEventHub      func()
 }
 
 const (
@@ -38,7 +54,14 @@ type Response[T any] struct {
 }
 
 func NewServer(port int, database *database.Connection, mode string) *Server {
-	server := Server{db: database}
+	server := Server{
+		db:      database,
+		storage: storage.NewLocalStorage(filepath.Join(".openticket", "attachments")),
+		events:  newEventHub(),
+	}
+
+	server.SetWebhookConfig(WebhookConfig{})
+	server.SetSessionMaxAge(DefaultSessionMaxAge)
 
 	server.validate = validator.New(validator.WithRequiredStructEnabled())
 	server.validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -50,6 +73,7 @@ func NewServer(port int, database *database.Connection, mode string) *Server {
 
 		return name
 	})
+	server.validate.RegisterValidation("reply_template", validateReplyTemplate)
 
 	switch mode {
 	case TestMode:
@@ -76,8 +100,18 @@ func NewServer(port int, database *database.Connection, mode string) *Server {
 			auth.DELETE("/users/:id", server.deleteUser)
 			auth.PATCH("/users/:id", server.patchUser)
 
+			auth.GET("/settings", server.settings)
+			auth.PATCH("/settings", server.patchSettings)
+
 			auth.GET("/labels", server.labels)
 			auth.POST("/labels", server.createLabel)
+			auth.PATCH("/labels/:labelId", server.patchLabel)
+			auth.DELETE("/labels/:labelId", server.deleteLabel)
+
+			auth.GET("/label-groups", server.labelGroups)
+			auth.POST("/label-groups", server.createLabelGroup)
+			auth.PATCH("/label-groups/:groupId", server.patchLabelGroup)
+			auth.DELETE("/label-groups/:groupId", server.deleteLabelGroup)
 
 			auth.POST("/tickets", server.createTicket)
 			auth.GET("/tickets", server.tickets)
@@ -85,14 +119,68 @@ func NewServer(port int, database *database.Connection, mode string) *Server {
 			auth.DELETE("/tickets/:ticketId", server.deleteTicket)
 			auth.PATCH("/tickets/:ticketId", server.patchTicket)
 			auth.PATCH("/tickets/:ticketId/status", server.patchTicketStatus)
+			auth.POST("/tickets/:ticketId/watchers", server.watchTicket)
+			auth.DELETE("/tickets/:ticketId/watchers", server.unwatchTicket)
+			auth.POST("/tickets/:ticketId/reactions", server.createTicketReaction)
+			auth.DELETE("/tickets/:ticketId/reactions/:emoji", server.deleteTicketReaction)
 
 			auth.GET("/tickets/:ticketId/comments", server.comments)
 			auth.POST("/tickets/:ticketId/comments", server.createComment)
 			auth.DELETE("/tickets/:ticketId/comments/:commentId", server.deleteComment)
 			auth.PATCH("/tickets/:ticketId/comments/:commentId", server.patchComment)
+			auth.GET("/tickets/:ticketId/comments/:commentId/revisions", server.commentRevisions)
+			auth.POST("/tickets/:ticketId/comments/:commentId/attachments", server.createCommentAttachment)
+			auth.POST("/tickets/:ticketId/comments/:commentId/reactions", server.createCommentReaction)
+			auth.DELETE("/tickets/:ticketId/comments/:commentId/reactions/:emoji", server.deleteCommentReaction)
+
+			auth.GET("/tickets/:ticketId/attachments", server.attachments)
+			auth.POST("/tickets/:ticketId/attachments", server.createTicketAttachment)
+			auth.GET("/tickets/:ticketId/attachments/:attachmentId", server.downloadAttachment)
+			auth.DELETE("/tickets/:ticketId/attachments/:attachmentId", server.deleteAttachment)
+
+			auth.GET("/reply-templates", server.replyTemplates)
+			auth.POST("/reply-templates", server.createReplyTemplate)
+			auth.GET("/reply-templates/:templateId", server.replyTemplate)
+			auth.PATCH("/reply-templates/:templateId", server.patchReplyTemplate)
+			auth.DELETE("/reply-templates/:templateId", server.deleteReplyTemplate)
+
+			auth.GET("/events/stream", server.eventStream)
+
+			auth.GET("/notifications", server.notifications)
+			auth.POST("/notifications/read-all", server.markAllNotificationsAsRead)
+			auth.GET("/notifications/preferences", server.notificationPreferences)
+			auth.PATCH("/notifications/preferences", server.patchNotificationPreferences)
+			auth.POST("/notifications/:notificationId/read", server.markNotificationAsRead)
+
+			auth.GET("/reports/workload", server.workload)
+
+			auth.GET("/assignment-rules", server.assignmentRules)
+			auth.POST("/assignment-rules", server.createAssignmentRule)
+			auth.DELETE("/assignment-rules/:ruleId", server.deleteAssignmentRule)
 
 			auth.POST("/tickets/:ticketId/assignments", server.createAssignment)
 			auth.DELETE("/tickets/:ticketId/assignments/:assignmentId", server.deleteAssignment)
+			auth.POST("/tickets/:ticketId/team-assignments", server.createTeamAssignment)
+			auth.DELETE("/tickets/:ticketId/team-assignments/:assignmentId", server.deleteTeamAssignment)
+
+			auth.POST("/inbound/email", server.inboundEmail)
+
+			auth.GET("/webhooks", server.webhooks)
+			auth.POST("/webhooks", server.createWebhook)
+			auth.GET("/webhooks/:webhookId", server.webhook)
+			auth.PATCH("/webhooks/:webhookId", server.patchWebhook)
+			auth.DELETE("/webhooks/:webhookId", server.deleteWebhook)
+			auth.POST("/webhooks/:webhookId/ping", server.pingWebhook)
+			auth.GET("/webhooks/:webhookId/deliveries", server.webhookDeliveries)
+			auth.POST("/webhooks/:webhookId/deliveries/:deliveryId/redeliver", server.redeliverWebhook)
+
+			auth.GET("/teams", server.teams)
+			auth.POST("/teams", server.createTeam)
+			auth.GET("/teams/:teamId", server.team)
+			auth.PATCH("/teams/:teamId", server.patchTeam)
+			auth.DELETE("/teams/:teamId", server.deleteTeam)
+			auth.POST("/teams/:teamId/members", server.addTeamMember)
+			auth.DELETE("/teams/:teamId/members/:userId", server.removeTeamMember)
 		}
 	}
 
@@ -104,11 +192,24 @@ func NewServer(port int, database *database.Connection, mode string) *Server {
 	return &server
 }
 
+// SetAddr changes the address the server listens on, for example
+// "127.0.0.1:8080". It must be called before Start.
+func (server *Server) SetAddr(addr string) {
+	server.httpServer.Addr = addr
+}
+
+func (server *Server) SetStorage(s storage.Storage) {
+	server.storage = s
+}
+
 func (server *Server) Extend(f func(r *gin.Engine)) {
 	f(server.router)
 }
 
 func (server *Server) Start() {
+	server.startEmailWorker()
+	server.startWebhookWorker()
+	server.startEventHub()
 	err := server.httpServer.ListenAndServe()
 	if err != nil && err != http.ErrServerClosed {
 		log.Fatal("error starting server. " + err.Error())
@@ -116,11 +217,27 @@ func (server *Server) Start() {
 }
 
 func (server *Server) URL() string {
-	return "http://localhost" + server.httpServer.Addr
+	host, port, err := net.SplitHostPort(server.httpServer.Addr)
+	if err != nil {
+		return "http://localhost" + server.httpServer.Addr
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
 }
 
 func (server *Server) Close() {
 	server.httpServer.Close()
+	if server.stopEmailWorker != nil {
+		server.stopEmailWorker()
+	}
+	if server.stopWebhookWorker != nil {
+		server.stopWebhookWorker()
+	}
+	if server.stopEventHub != nil {
+		server.stopEventHub()
+	}
 	server.db.Close()
 }
 
@@ -132,16 +249,18 @@ func (server *Server) jsonReq(c *gin.Context, req any) {
 		return
 	}
 
-	validationErrors := err.(validator.ValidationErrors)
-	apiErrors := make([]ValidationError, 0, len((validationErrors)))
-	for _, validationError := range validationErrors {
+	c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{Errors: validationErrors(err.(validator.ValidationErrors))})
+}
+
+func validationErrors(errs validator.ValidationErrors) []ValidationError {
+	apiErrors := make([]ValidationError, 0, len(errs))
+	for _, validationError := range errs {
 		apiErrors = append(apiErrors, ValidationError{
 			Field:     validationError.Field(),
 			Validator: validationError.Tag(),
 		})
 	}
-
-	c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{Errors: apiErrors})
+	return apiErrors
 }
 
 type PermissionDeniedError struct {
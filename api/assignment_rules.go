@@ -0,0 +1,261 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// autoAssign picks an assignee for a new ticket using the first assignment
+// rule that matches its labels and has someone available. Rules without a
+// label match every ticket and are tried last. It returns a zero user id when
+// no rule picks anyone.
+func autoAssign(ctx context.Context, qtx *sqlc.Queries, labels []string) (int32, int32, error) {
+	rules, err := qtx.GetAssignmentRules(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, rule := range rules {
+		if rule.Label.Valid && !slices.Contains(labels, rule.Label.String) {
+			continue
+		}
+
+		userID, err := pickAssignee(ctx, qtx, rule)
+		if err != nil {
+			return 0, 0, err
+		}
+		if userID != 0 {
+			return rule.ID, userID, nil
+		}
+	}
+	return 0, 0, nil
+}
+
+// pickAssignee skips deactivated and out of office users.
+func pickAssignee(ctx context.Context, qtx *sqlc.Queries, rule sqlc.GetAssignmentRulesRow) (int32, error) {
+	if rule.Strategy == sqlc.AssignmentStrategyFixed {
+		user, err := qtx.GetUserByID(ctx, rule.UserID.Int32)
+		if err != nil {
+			return 0, err
+		}
+		if user.Deactivated || user.OutOfOffice {
+			return 0, nil
+		}
+		return user.ID, nil
+	}
+
+	candidates, err := qtx.GetAssignmentCandidates(ctx, rule.TeamID.Int32)
+	if err != nil {
+		return 0, err
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	pick := candidates[0]
+	switch rule.Strategy {
+	case sqlc.AssignmentStrategyLeastOpen:
+		for _, candidate := range candidates[1:] {
+			if candidate.OpenTickets < pick.OpenTickets {
+				pick = candidate
+			}
+		}
+	case sqlc.AssignmentStrategyRoundRobin:
+		// Only the chosen rule is locked so concurrent tickets take turns
+		// without serializing on every rule.
+		locked, err := qtx.LockAssignmentRuleByID(ctx, rule.ID)
+		if err == pgx.ErrNoRows {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		// Candidates are sorted by id so the next one after the last assignee
+		// takes the turn, wrapping around to the first.
+		for _, candidate := range candidates {
+			if candidate.ID > locked.LastAssignedTo.Int32 {
+				pick = candidate
+				break
+			}
+		}
+
+		err = qtx.UpdateAssignmentRuleLastAssignedTo(ctx, sqlc.UpdateAssignmentRuleLastAssignedToParams{
+			ID:             rule.ID,
+			LastAssignedTo: pgtype.Int4{Int32: pick.ID, Valid: true},
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return pick.ID, nil
+}
+
+type AssignmentRule struct {
+	ID       int32  `json:"id"`
+	Label    string `json:"label,omitempty"`
+	Strategy string `json:"strategy"`
+	TeamID   int32  `json:"team_id,omitempty"`
+	UserID   int32  `json:"user_id,omitempty"`
+}
+
+func assignmentRuleResponse(rule sqlc.AssignmentRule, label string) AssignmentRule {
+	return AssignmentRule{
+		ID:       rule.ID,
+		Label:    label,
+		Strategy: string(rule.Strategy),
+		TeamID:   rule.TeamID.Int32,
+		UserID:   rule.UserID.Int32,
+	}
+}
+
+type AssignmentRuleNotFoundError struct{}
+
+func (e AssignmentRuleNotFoundError) Error() string {
+	return "assignment rule not found"
+}
+
+type AssignmentRulesResponse = Response[[]AssignmentRule]
+
+func (server *Server) assignmentRules(c *gin.Context) {
+	rules, err := server.db.Queries().GetAssignmentRules(c)
+	if err != nil && err != pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get assignment rules"})
+		return
+	}
+
+	data := make([]AssignmentRule, len(rules))
+	for i, rule := range rules {
+		data[i] = assignmentRuleResponse(sqlc.AssignmentRule{
+			ID:       rule.ID,
+			Strategy: rule.Strategy,
+			TeamID:   rule.TeamID,
+			UserID:   rule.UserID,
+		}, rule.Label.String)
+	}
+	c.JSON(http.StatusOK, AssignmentRulesResponse{Data: data})
+}
+
+type CreateAssignmentRuleRequest struct {
+	// Label limits the rule to tickets with the label, rules without one
+	// apply to every ticket.
+	Label    string `json:"label,omitempty"`
+	Strategy string `json:"strategy" validate:"required,oneof=round_robin least_open fixed"`
+	TeamID   int32  `json:"team_id,omitempty" validate:"required_unless=Strategy fixed"`
+	UserID   int32  `json:"user_id,omitempty" validate:"required_if=Strategy fixed"`
+}
+
+type CreateAssignmentRuleResponse = Response[AssignmentRule]
+
+func (server *Server) createAssignmentRule(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can create assignment rules"})
+		return
+	}
+
+	var req CreateAssignmentRuleRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	params := sqlc.CreateAssignmentRuleParams{
+		Strategy:  sqlc.AssignmentStrategy(req.Strategy),
+		CreatedBy: user.ID,
+	}
+
+	var rule sqlc.AssignmentRule
+	err := server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		if req.Label != "" {
+			label, err := qtx.GetLabelByName(ctx, req.Label)
+			if err != nil {
+				return UnknownLabelsError{Fields: []string{"label"}}
+			}
+			params.LabelID = pgtype.Int4{Int32: label.ID, Valid: true}
+		}
+
+		if params.Strategy == sqlc.AssignmentStrategyFixed {
+			_, err := qtx.GetUserByID(ctx, req.UserID)
+			if err != nil {
+				return UserNotFoundError{}
+			}
+			params.UserID = pgtype.Int4{Int32: req.UserID, Valid: true}
+		} else {
+			_, err := qtx.GetTeamByID(ctx, req.TeamID)
+			if err != nil {
+				return TeamNotFoundError{}
+			}
+			params.TeamID = pgtype.Int4{Int32: req.TeamID, Valid: true}
+		}
+
+		var err error
+		rule, err = qtx.CreateAssignmentRule(ctx, params)
+		return err
+	})
+
+	switch err := err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateAssignmentRuleResponse{Data: assignmentRuleResponse(rule, req.Label)})
+	case UnknownLabelsError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "label not found",
+			Errors:  err.ValidationErrors(),
+		})
+	case UserNotFoundError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "user not found",
+			Errors: []ValidationError{
+				{Field: "user_id", Validator: "exists"},
+			},
+		})
+	case TeamNotFoundError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "team not found",
+			Errors: []ValidationError{
+				{Field: "team_id", Validator: "exists"},
+			},
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create assignment rule"})
+	}
+}
+
+func (server *Server) deleteAssignmentRule(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can delete assignment rules"})
+		return
+	}
+
+	ruleId, err := strconv.ParseInt(c.Param("ruleId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "assignment rule not found"})
+		return
+	}
+
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		rule, err := qtx.GetAssignmentRuleByID(ctx, int32(ruleId))
+		if err != nil {
+			return AssignmentRuleNotFoundError{}
+		}
+
+		return qtx.DeleteAssignmentRuleByID(ctx, rule.ID)
+	})
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case AssignmentRuleNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete assignment rule"})
+	}
+}
@@ -0,0 +1,170 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignmentRules(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	_, memberA := testutil.NewMember(t, &sdk)
+	_, memberB := testutil.NewMember(t, &sdk)
+	_, owner := testutil.NewMember(t, &sdk)
+
+	var teamRes api.CreateTeamResponse
+	_, err := sdk.CreateTeam(api.CreateTeamRequest{
+		Name:    "support",
+		Members: []int32{memberA.Data.ID, memberB.Data.ID},
+	}, &teamRes)
+	require.NoError(t, err, "error creating team")
+
+	sdk.CreateLabel(api.CreateLabelRequest{Name: "billing"}, nil)
+
+	var fixedRes api.CreateAssignmentRuleResponse
+	httpRes, err := sdk.CreateAssignmentRule(api.CreateAssignmentRuleRequest{
+		Label:    "billing",
+		Strategy: "fixed",
+		UserID:   owner.Data.ID,
+	}, &fixedRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+	var roundRobinRes api.CreateAssignmentRuleResponse
+	httpRes, err = sdk.CreateAssignmentRule(api.CreateAssignmentRuleRequest{
+		Strategy: "round_robin",
+		TeamID:   teamRes.Data.ID,
+	}, &roundRobinRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+	events := openEventStream(t, sdk, 0)
+
+	createTicket := func(t *testing.T, req api.CreateTicketRequest) api.Ticket {
+		req.Title = gofakeit.JobTitle()
+		req.Description = gofakeit.Sentence(10)
+
+		var res api.CreateTicketResponse
+		httpRes, err := sdk.CreateTicket(req, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		return res.Data
+	}
+
+	t.Run("success: label rule picks the fixed owner", func(t *testing.T) {
+		ticket := createTicket(t, api.CreateTicketRequest{Labels: []string{"billing"}})
+		require.Equal(t, []int32{owner.Data.ID}, ticket.AssignedTo)
+
+		assigned := waitForEvent(t, events, func(event streamEvent) bool {
+			data, ok := event.payload.Data.(map[string]any)
+			return ok && event.event == api.EventAssignmentCreated && data["ticket_id"] == float64(ticket.ID)
+		})
+		require.Equal(t, float64(fixedRes.Data.ID), assigned.payload.Data.(map[string]any)["rule_id"])
+	})
+
+	t.Run("success: round robin skips unavailable users", func(t *testing.T) {
+		outOfOffice := true
+		var userRes api.PatchUserResponse
+		_, err := sdk.PatchUser(owner.Data.ID, api.PatchUserRequest{OutOfOffice: &outOfOffice}, &userRes)
+		require.NoError(t, err, "error updating user")
+
+		ticket := createTicket(t, api.CreateTicketRequest{Labels: []string{"billing"}})
+		require.Equal(t, []int32{memberA.Data.ID}, ticket.AssignedTo)
+
+		ticket = createTicket(t, api.CreateTicketRequest{})
+		require.Equal(t, []int32{memberB.Data.ID}, ticket.AssignedTo)
+
+		ticket = createTicket(t, api.CreateTicketRequest{})
+		require.Equal(t, []int32{memberA.Data.ID}, ticket.AssignedTo)
+
+		deactivated := true
+		_, err = sdk.PatchUser(memberB.Data.ID, api.PatchUserRequest{Deactivated: &deactivated}, &userRes)
+		require.NoError(t, err, "error updating user")
+
+		ticket = createTicket(t, api.CreateTicketRequest{})
+		require.Equal(t, []int32{memberA.Data.ID}, ticket.AssignedTo)
+	})
+
+	t.Run("success: explicit assignees skip the rules", func(t *testing.T) {
+		ticket := createTicket(t, api.CreateTicketRequest{AssignedTo: []int32{owner.Data.ID}})
+		require.Equal(t, []int32{owner.Data.ID}, ticket.AssignedTo)
+	})
+
+	t.Run("error: members can't create rules", func(t *testing.T) {
+		member, _ := testutil.NewMember(t, &sdk)
+		memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+		var res api.CreateAssignmentRuleResponse
+		httpRes, err := memberSdk.CreateAssignmentRule(api.CreateAssignmentRuleRequest{
+			Strategy: "least_open",
+			TeamID:   teamRes.Data.ID,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+
+	t.Run("error: fixed rule without user", func(t *testing.T) {
+		var res api.CreateAssignmentRuleResponse
+		httpRes, err := sdk.CreateAssignmentRule(api.CreateAssignmentRuleRequest{Strategy: "fixed"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "user_id", "required_if")
+	})
+}
+
+func TestAssignmentRules_LeastOpen(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	_, memberA := testutil.NewMember(t, &sdk)
+	_, memberB := testutil.NewMember(t, &sdk)
+
+	var teamRes api.CreateTeamResponse
+	_, err := sdk.CreateTeam(api.CreateTeamRequest{
+		Name:    "support",
+		Members: []int32{memberA.Data.ID, memberB.Data.ID},
+	}, &teamRes)
+	require.NoError(t, err, "error creating team")
+
+	var ticketRes api.CreateTicketResponse
+	for range 2 {
+		_, err = sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+			AssignedTo:  []int32{memberA.Data.ID},
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+	}
+
+	var ruleRes api.CreateAssignmentRuleResponse
+	_, err = sdk.CreateAssignmentRule(api.CreateAssignmentRuleRequest{
+		Strategy: "least_open",
+		TeamID:   teamRes.Data.ID,
+	}, &ruleRes)
+	require.NoError(t, err, "error creating assignment rule")
+
+	expected := []int32{memberB.Data.ID, memberB.Data.ID, memberA.Data.ID}
+	for _, userID := range expected {
+		var res api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &res)
+		require.NoError(t, err, "error creating ticket")
+		require.Equal(t, []int32{userID}, res.Data.AssignedTo)
+	}
+}
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 
 	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type CreateAssignmentRequest struct {
@@ -16,15 +20,119 @@ type Assignment struct {
 	ID       int32 `json:"id"`
 	TicketID int32 `json:"ticket_id"`
 	UserID   int32 `json:"user_id"`
+	// RuleID is the assignment rule that picked the user, if any.
+	RuleID int32 `json:"rule_id,omitempty"`
 }
 
 type CreateAssignmentResponse = Response[Assignment]
 
+func assignmentResponse(assignment sqlc.Assignment) Assignment {
+	return Assignment{
+		ID:       assignment.ID,
+		TicketID: assignment.TicketID,
+		UserID:   assignment.UserID,
+		RuleID:   assignment.RuleID.Int32,
+	}
+}
+
+type AssignmentNotFoundError struct{}
+
+func (e AssignmentNotFoundError) Error() string {
+	return "assignment not found"
+}
+
+type AssignmentAlreadyExistsError struct{}
+
+func (e AssignmentAlreadyExistsError) Error() string {
+	return "user is already assigned to the ticket"
+}
+
+type TeamAssignmentAlreadyExistsError struct{}
+
+func (e TeamAssignmentAlreadyExistsError) Error() string {
+	return "team is already assigned to the ticket"
+}
+
+type TeamAssignmentNotFoundError struct{}
+
+func (e TeamAssignmentNotFoundError) Error() string {
+	return "team assignment not found"
+}
+
+type UserDeactivatedError struct{}
+
+func (e UserDeactivatedError) Error() string {
+	return "user is deactivated"
+}
+
+// checkAssignee returns the user that is going to be assigned, failing with
+// UserNotFoundError or UserDeactivatedError when they can't be.
+func checkAssignee(ctx context.Context, qtx *sqlc.Queries, userID int32) (sqlc.User, error) {
+	assignee, err := qtx.GetUserByID(ctx, userID)
+	if err == pgx.ErrNoRows {
+		return sqlc.User{}, UserNotFoundError{}
+	}
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	if assignee.Deactivated {
+		return sqlc.User{}, UserDeactivatedError{}
+	}
+	return assignee, nil
+}
+
+// InvalidAssigneesError lists the users of a request that can't be assigned
+// as fields like assigned_to[1].
+type InvalidAssigneesError struct {
+	Errors []ValidationError
+}
+
+func (e InvalidAssigneesError) Error() string {
+	return "invalid assignees"
+}
+
+// ensureAssignees runs checkAssignee for every user that isn't in current
+// yet, reporting the failures as items of field.
+func ensureAssignees(ctx context.Context, qtx *sqlc.Queries, field string, userIDs []int32, current []int32) error {
+	var invalid InvalidAssigneesError
+	for i, userID := range userIDs {
+		if slices.Contains(current, userID) {
+			continue
+		}
+
+		_, err := checkAssignee(ctx, qtx, userID)
+		switch err.(type) {
+		case nil:
+			continue
+		case UserNotFoundError:
+			invalid.Errors = append(invalid.Errors, ValidationError{Field: fmt.Sprintf("%s[%d]", field, i), Validator: "exists"})
+		case UserDeactivatedError:
+			invalid.Errors = append(invalid.Errors, ValidationError{Field: fmt.Sprintf("%s[%d]", field, i), Validator: "active"})
+		default:
+			return err
+		}
+	}
+
+	if len(invalid.Errors) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+// Admins and the ticket's creator can assign anyone, other users can only
+// assign themselves.
+func canAssign(user *sqlc.User, ticket sqlc.GetTicketByIDRow, assigneeID int32) bool {
+	return user.Role == "admin" || ticket.CreatedBy == user.ID || assigneeID == user.ID
+}
+
 func (server *Server) createAssignment(c *gin.Context) {
 	user := server.AuthUserFromContext(c)
 
 	var req CreateAssignmentRequest
 	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
 
 	ticketId, err := strconv.ParseUint(c.Param("ticketId"), 10, 32)
 	if err != nil {
@@ -32,38 +140,273 @@ func (server *Server) createAssignment(c *gin.Context) {
 		return
 	}
 
-	assignment, err := server.db.Queries().CreateAssignment(c.Request.Context(), sqlc.CreateAssignmentParams{
-		TicketID:   int32(ticketId),
-		UserID:     req.UserID,
-		AssignedBy: user.ID,
-	})
+	var assignment sqlc.Assignment
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		ticket, err := qtx.GetTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return TicketNotFoundError{}
+		}
 
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create assignment"})
-		return
-	}
+		if !canAssign(user, ticket, req.UserID) {
+			return PermissionDeniedError{Message: "only admins and the ticket's creator can assign other users"}
+		}
+
+		assignee, err := checkAssignee(ctx, qtx, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(ticket.AssignedTo, assignee.ID) {
+			return AssignmentAlreadyExistsError{}
+		}
+
+		assignment, err = qtx.CreateAssignment(ctx, sqlc.CreateAssignmentParams{
+			TicketID:   ticket.ID,
+			UserID:     assignee.ID,
+			AssignedBy: user.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = watch(ctx, qtx, assignment.TicketID, assignment.UserID)
+		if err != nil {
+			return err
+		}
 
-	c.JSON(http.StatusCreated, CreateAssignmentResponse{
-		Data: Assignment{
+		err = publishEvent(ctx, qtx, EventAssignmentCreated, user, assignmentResponse(assignment))
+		if err != nil {
+			return err
+		}
+
+		return notify(ctx, qtx, NotificationEvent{
+			Type:     sqlc.NotificationTypeAssignment,
 			TicketID: assignment.TicketID,
-			UserID:   assignment.UserID,
-			ID:       assignment.ID,
-		},
+			ActorID:  user.ID,
+		}, assignment.UserID)
 	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateAssignmentResponse{Data: assignmentResponse(assignment)})
+	case TicketNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+	case UserNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "user not found"})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	case UserDeactivatedError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "user_id", Validator: "active"},
+			},
+		})
+	case AssignmentAlreadyExistsError:
+		c.AbortWithStatusJSON(http.StatusConflict, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create assignment"})
+	}
 }
 
 func (server *Server) deleteAssignment(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseUint(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
 	assignmentId, err := strconv.ParseUint(c.Param("assignmentId"), 10, 32)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "assignment not found"})
 		return
 	}
 
-	err = server.db.Queries().DeleteAssignment(c.Request.Context(), int32(assignmentId))
-	if err != nil {
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		ticket, err := qtx.GetTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return TicketNotFoundError{}
+		}
+
+		assignment, err := qtx.GetAssignmentByID(ctx, int32(assignmentId))
+		if err != nil || assignment.TicketID != ticket.ID {
+			return AssignmentNotFoundError{}
+		}
+
+		if !canAssign(user, ticket, assignment.UserID) {
+			return PermissionDeniedError{Message: "only admins and the ticket's creator can unassign other users"}
+		}
+
+		assignment, err = qtx.DeleteAssignment(ctx, assignment.ID)
+		if err != nil {
+			return err
+		}
+
+		return publishEvent(ctx, qtx, EventAssignmentDeleted, user, assignmentResponse(assignment))
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusOK, Response[any]{Message: "assignment deleted"})
+	case TicketNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+	case AssignmentNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	default:
 		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete assignment"})
+	}
+}
+
+type CreateTeamAssignmentRequest struct {
+	TeamID int32 `json:"team_id" validate:"required,number"`
+}
+
+type TeamAssignment struct {
+	ID       int32 `json:"id"`
+	TicketID int32 `json:"ticket_id"`
+	TeamID   int32 `json:"team_id"`
+}
+
+type CreateTeamAssignmentResponse = Response[TeamAssignment]
+
+func teamAssignmentResponse(assignment sqlc.TeamAssignment) TeamAssignment {
+	return TeamAssignment{
+		ID:       assignment.ID,
+		TicketID: assignment.TicketID,
+		TeamID:   assignment.TeamID,
+	}
+}
+
+func (server *Server) createTeamAssignment(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	var req CreateTeamAssignmentRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
 		return
 	}
 
-	c.JSON(http.StatusOK, Response[any]{Message: "assignment deleted"})
+	ticketId, err := strconv.ParseUint(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	var assignment sqlc.TeamAssignment
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		ticket, err := qtx.GetTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return TicketNotFoundError{}
+		}
+
+		_, err = qtx.GetTeamByID(ctx, req.TeamID)
+		if err == pgx.ErrNoRows {
+			return UnknownTeamsError{Fields: []string{"team_id"}}
+		}
+		if err != nil {
+			return err
+		}
+
+		// A team is never the user itself, so only admins and the ticket's
+		// creator can assign it.
+		if !canAssign(user, ticket, 0) {
+			return PermissionDeniedError{Message: "only admins and the ticket's creator can assign teams"}
+		}
+
+		if slices.Contains(ticket.AssignedTeams, req.TeamID) {
+			return TeamAssignmentAlreadyExistsError{}
+		}
+
+		assignment, err = qtx.CreateTeamAssignment(ctx, sqlc.CreateTeamAssignmentParams{
+			TicketID:   ticket.ID,
+			TeamID:     req.TeamID,
+			AssignedBy: user.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = publishEvent(ctx, qtx, EventTeamAssignmentCreated, user, teamAssignmentResponse(assignment))
+		if err != nil {
+			return err
+		}
+
+		return notifyTeamAssignment(ctx, qtx, assignment.TicketID, assignment.TeamID, user.ID)
+	})
+
+	switch err := err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateTeamAssignmentResponse{Data: teamAssignmentResponse(assignment)})
+	case TicketNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+	case UnknownTeamsError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors:  err.ValidationErrors(),
+		})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	case TeamAssignmentAlreadyExistsError:
+		c.AbortWithStatusJSON(http.StatusConflict, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create team assignment"})
+	}
+}
+
+func (server *Server) deleteTeamAssignment(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseUint(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	assignmentId, err := strconv.ParseUint(c.Param("assignmentId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team assignment not found"})
+		return
+	}
+
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		ticket, err := qtx.GetTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return TicketNotFoundError{}
+		}
+
+		assignment, err := qtx.GetTeamAssignmentByID(ctx, int32(assignmentId))
+		if err != nil || assignment.TicketID != ticket.ID {
+			return TeamAssignmentNotFoundError{}
+		}
+
+		// A team is never the user itself, so only admins and the ticket's
+		// creator can unassign it.
+		if !canAssign(user, ticket, 0) {
+			return PermissionDeniedError{Message: "only admins and the ticket's creator can unassign teams"}
+		}
+
+		assignment, err = qtx.DeleteTeamAssignment(ctx, assignment.ID)
+		if err != nil {
+			return err
+		}
+
+		return publishEvent(ctx, qtx, EventTeamAssignmentDeleted, user, teamAssignmentResponse(assignment))
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusOK, Response[any]{Message: "team assignment deleted"})
+	case TicketNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+	case TeamAssignmentNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete team assignment"})
+	}
 }
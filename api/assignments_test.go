@@ -51,7 +51,274 @@ func TestAPI_CreateAssignment(t *testing.T) {
 	t.Run("success: delete assignment", func(t *testing.T) {
 		t.Parallel()
 
-		_, err = sdk.DeleteAssignment(1)
+		_, member := testutil.NewMember(t, &sdk)
+
+		var assignmentRes api.CreateAssignmentResponse
+		_, err := sdk.CreateAssignment(ticketRes.Data.ID, member.Data.ID, &assignmentRes)
+		require.NoError(t, err, "error creating assignment")
+
+		httpRes, err := sdk.DeleteAssignment(ticketRes.Data.ID, assignmentRes.Data.ID)
 		require.NoError(t, err, "error deleting assignment")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+
+		httpRes, err = sdk.DeleteAssignment(ticketRes.Data.ID, assignmentRes.Data.ID)
+		require.NoError(t, err, "error deleting assignment")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("success: members can assign themselves", func(t *testing.T) {
+		t.Parallel()
+
+		member, memberRes := testutil.NewMember(t, &sdk)
+		memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+		var assignmentRes api.CreateAssignmentResponse
+		httpRes, err := memberSdk.CreateAssignment(ticketRes.Data.ID, memberRes.Data.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		httpRes, err = memberSdk.DeleteAssignment(ticketRes.Data.ID, assignmentRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	})
+
+	t.Run("error: members can't assign others", func(t *testing.T) {
+		t.Parallel()
+
+		member, _ := testutil.NewMember(t, &sdk)
+		memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+		_, other := testutil.NewMember(t, &sdk)
+
+		var assignmentRes api.CreateAssignmentResponse
+		httpRes, err := memberSdk.CreateAssignment(ticketRes.Data.ID, other.Data.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+
+	t.Run("error: duplicate assignment", func(t *testing.T) {
+		t.Parallel()
+
+		_, member := testutil.NewMember(t, &sdk)
+
+		var assignmentRes api.CreateAssignmentResponse
+		httpRes, err := sdk.CreateAssignment(ticketRes.Data.ID, member.Data.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		httpRes, err = sdk.CreateAssignment(ticketRes.Data.ID, member.Data.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusConflict, httpRes.StatusCode)
+	})
+
+	t.Run("error: unknown ticket and user", func(t *testing.T) {
+		t.Parallel()
+
+		var assignmentRes api.CreateAssignmentResponse
+		httpRes, err := sdk.CreateAssignment(ticketRes.Data.ID+1000, userRes.Data.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+
+		httpRes, err = sdk.CreateAssignment(ticketRes.Data.ID, userRes.Data.ID+1000, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("error: deactivated user", func(t *testing.T) {
+		t.Parallel()
+
+		_, member := testutil.NewMember(t, &sdk)
+		deactivated := true
+		var userRes api.PatchUserResponse
+		_, err := sdk.PatchUser(member.Data.ID, api.PatchUserRequest{Deactivated: &deactivated}, &userRes)
+		require.NoError(t, err, "error deactivating user")
+		require.True(t, userRes.Data.Deactivated)
+
+		var assignmentRes api.CreateAssignmentResponse
+		httpRes, err := sdk.CreateAssignment(ticketRes.Data.ID, member.Data.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, assignmentRes.Errors, "user_id", "active")
+	})
+
+	t.Run("error: invalid assignees on ticket create and patch", func(t *testing.T) {
+		t.Parallel()
+
+		_, member := testutil.NewMember(t, &sdk)
+		deactivated := true
+		var patchUserRes api.PatchUserResponse
+		_, err := sdk.PatchUser(member.Data.ID, api.PatchUserRequest{Deactivated: &deactivated}, &patchUserRes)
+		require.NoError(t, err, "error deactivating user")
+
+		var createRes api.CreateTicketResponse
+		httpRes, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.Job().Title,
+			Description: gofakeit.Sentence(10),
+			AssignedTo:  []int32{userRes.Data.ID + 1000, member.Data.ID},
+		}, &createRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, createRes.Errors, "assigned_to[0]", "exists")
+		testutil.RequireValidationError(t, createRes.Errors, "assigned_to[1]", "active")
+
+		var patchRes api.PatchTicketResponse
+		httpRes, err = sdk.PatchTicket(ticketRes.Data.ID, api.PatchTicketRequest{
+			AssignedTo: []int32{userRes.Data.ID + 1000, member.Data.ID},
+		}, &patchRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, patchRes.Errors, "assignments[0]", "exists")
+		testutil.RequireValidationError(t, patchRes.Errors, "assignments[1]", "active")
+
+		var ticket api.TicketResponse
+		_, err = sdk.Ticket(ticketRes.Data.ID, &ticket)
+		require.NoError(t, err, "error getting ticket")
+		require.NotContains(t, ticket.Data.AssignedTo, member.Data.ID)
+	})
+
+	t.Run("error: assignment of another ticket", func(t *testing.T) {
+		t.Parallel()
+
+		_, member := testutil.NewMember(t, &sdk)
+
+		var assignmentRes api.CreateAssignmentResponse
+		_, err := sdk.CreateAssignment(ticketRes.Data.ID, member.Data.ID, &assignmentRes)
+		require.NoError(t, err, "error creating assignment")
+
+		var otherTicketRes api.CreateTicketResponse
+		_, err = sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.Job().Title,
+			Description: gofakeit.Sentence(10),
+		}, &otherTicketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		httpRes, err := sdk.DeleteAssignment(otherTicketRes.Data.ID, assignmentRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+}
+
+func TestAPI_TeamAssignments(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	createTicket := func(t *testing.T) api.Ticket {
+		var ticketRes api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.Job().Title,
+			Description: gofakeit.Sentence(10),
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+		return ticketRes.Data
+	}
+
+	createTeam := func(t *testing.T) api.Team {
+		var teamRes api.CreateTeamResponse
+		_, err := sdk.CreateTeam(api.CreateTeamRequest{Name: gofakeit.UUID()}, &teamRes)
+		require.NoError(t, err, "error creating team")
+		return teamRes.Data
+	}
+
+	t.Run("success: delete team assignment", func(t *testing.T) {
+		t.Parallel()
+
+		ticket := createTicket(t)
+		team := createTeam(t)
+
+		var assignmentRes api.CreateTeamAssignmentResponse
+		httpRes, err := sdk.CreateTeamAssignment(ticket.ID, team.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		httpRes, err = sdk.DeleteTeamAssignment(ticket.ID, assignmentRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+
+		httpRes, err = sdk.DeleteTeamAssignment(ticket.ID, assignmentRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("error: team assignment of another ticket", func(t *testing.T) {
+		t.Parallel()
+
+		ticket := createTicket(t)
+		other := createTicket(t)
+		team := createTeam(t)
+
+		var assignmentRes api.CreateTeamAssignmentResponse
+		_, err := sdk.CreateTeamAssignment(ticket.ID, team.ID, &assignmentRes)
+		require.NoError(t, err, "error creating team assignment")
+
+		httpRes, err := sdk.DeleteTeamAssignment(other.ID, assignmentRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("error: members can't unassign teams", func(t *testing.T) {
+		t.Parallel()
+
+		ticket := createTicket(t)
+		team := createTeam(t)
+		member, _ := testutil.NewMember(t, &sdk)
+		memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+		var assignmentRes api.CreateTeamAssignmentResponse
+		_, err := sdk.CreateTeamAssignment(ticket.ID, team.ID, &assignmentRes)
+		require.NoError(t, err, "error creating team assignment")
+
+		httpRes, err := memberSdk.DeleteTeamAssignment(ticket.ID, assignmentRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+
+	t.Run("error: duplicate team assignment", func(t *testing.T) {
+		t.Parallel()
+
+		ticket := createTicket(t)
+		team := createTeam(t)
+
+		var assignmentRes api.CreateTeamAssignmentResponse
+		httpRes, err := sdk.CreateTeamAssignment(ticket.ID, team.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		httpRes, err = sdk.CreateTeamAssignment(ticket.ID, team.ID, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusConflict, httpRes.StatusCode)
+	})
+
+	t.Run("error: unknown team", func(t *testing.T) {
+		t.Parallel()
+
+		ticket := createTicket(t)
+		team := createTeam(t)
+
+		var assignmentRes api.CreateTeamAssignmentResponse
+		httpRes, err := sdk.CreateTeamAssignment(ticket.ID, team.ID+1000, &assignmentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, assignmentRes.Errors, "team_id", "exists")
+
+		var ticketRes api.CreateTicketResponse
+		httpRes, err = sdk.CreateTicket(api.CreateTicketRequest{
+			Title:         gofakeit.Job().Title,
+			Description:   gofakeit.Sentence(10),
+			AssignedTeams: []int32{team.ID, team.ID + 1000},
+		}, &ticketRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, ticketRes.Errors, "assigned_teams[1]", "exists")
+
+		var patchRes api.PatchTicketResponse
+		httpRes, err = sdk.PatchTicket(ticket.ID, api.PatchTicketRequest{
+			AssignedTeams: []int32{team.ID + 1000},
+		}, &patchRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, patchRes.Errors, "assigned_teams[0]", "exists")
 	})
 }
@@ -0,0 +1,374 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/BrunoQuaresma/openticket/api/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const MaxAttachmentSize = 10 << 20
+
+// The content type is sniffed from the uploaded bytes instead of trusting the
+// one sent by the client.
+var AllowedAttachmentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"text/plain",
+	"application/pdf",
+	"application/zip",
+	"application/x-gzip",
+}
+
+type Attachment struct {
+	ID          int32  `json:"id"`
+	TicketID    int32  `json:"ticket_id"`
+	CommentID   int32  `json:"comment_id,omitempty"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	UploadedBy  User   `json:"uploaded_by"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type AttachmentsResponse = Response[[]Attachment]
+
+func (server *Server) attachments(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	attachmentRows, err := server.db.Queries().GetAttachmentsByTicketID(c, sqlc.GetAttachmentsByTicketIDParams{
+		TicketID:        int32(ticketId),
+		IncludeInternal: canViewInternalComments(user),
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get attachments"})
+		return
+	}
+
+	attachments := make([]Attachment, len(attachmentRows))
+	for i, attachment := range attachmentRows {
+		attachments[i] = Attachment{
+			ID:          attachment.ID,
+			TicketID:    attachment.TicketID,
+			CommentID:   attachment.CommentID.Int32,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			SHA256:      attachment.Sha256,
+			CreatedAt:   attachment.CreatedAt.Time.Format(time.RFC3339),
+			UploadedBy: User{
+				ID:       attachment.User.ID,
+				Name:     attachment.User.Name,
+				Username: attachment.User.Username,
+				Email:    attachment.User.Email,
+				Role:     string(attachment.User.Role),
+			},
+		}
+	}
+
+	c.JSON(http.StatusOK, AttachmentsResponse{Data: attachments})
+}
+
+type CreateAttachmentResponse = Response[Attachment]
+
+func (server *Server) createTicketAttachment(c *gin.Context) {
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	_, err = server.db.Queries().GetTicketByID(c, int32(ticketId))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	server.upload(c, int32(ticketId), pgtype.Int4{})
+}
+
+func (server *Server) createCommentAttachment(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	commentId, err := strconv.ParseInt(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
+	comment, err := server.db.Queries().GetCommentByID(c, int32(commentId))
+	if err != nil || comment.TicketID != int32(ticketId) {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+	if comment.Visibility == sqlc.CommentVisibilityInternal && !canViewInternalComments(user) {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
+	server.upload(c, comment.TicketID, pgtype.Int4{Int32: comment.ID, Valid: true})
+}
+
+func (server *Server) upload(c *gin.Context, ticketID int32, commentID pgtype.Int4) {
+	user := server.AuthUserFromContext(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxAttachmentSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, Response[any]{
+				Message: AttachmentTooLargeError{}.Error(),
+				Errors:  []ValidationError{{Field: "file", Validator: "max"}},
+			})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Errors: []ValidationError{{Field: "file", Validator: "required"}},
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to read attachment"})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to read attachment"})
+		return
+	}
+
+	attachment, err := server.saveAttachment(c.Request.Context(), server.db.Queries(), ticketID, commentID, user.ID, fileHeader.Filename, content)
+	switch err.(type) {
+	case nil:
+	case AttachmentTooLargeError:
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, Response[any]{
+			Message: err.Error(),
+			Errors:  []ValidationError{{Field: "file", Validator: "max"}},
+		})
+		return
+	case UnsupportedAttachmentTypeError:
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, Response[any]{
+			Message: err.Error(),
+			Errors:  []ValidationError{{Field: "file", Validator: "mimetype"}},
+		})
+		return
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create attachment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAttachmentResponse{
+		Data: Attachment{
+			ID:          attachment.ID,
+			TicketID:    attachment.TicketID,
+			CommentID:   attachment.CommentID.Int32,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			SHA256:      attachment.Sha256,
+			CreatedAt:   attachment.CreatedAt.Time.Format(time.RFC3339),
+			UploadedBy: User{
+				ID:       user.ID,
+				Name:     user.Name,
+				Username: user.Username,
+				Email:    user.Email,
+				Role:     string(user.Role),
+			},
+		},
+	})
+}
+
+type AttachmentTooLargeError struct{}
+
+func (e AttachmentTooLargeError) Error() string {
+	return fmt.Sprintf("attachments can't be larger than %d bytes", MaxAttachmentSize)
+}
+
+type UnsupportedAttachmentTypeError struct{}
+
+func (e UnsupportedAttachmentTypeError) Error() string {
+	return "unsupported attachment type"
+}
+
+// saveAttachment validates and stores the content of an attachment. The
+// stored object is removed when the attachment can't be recorded.
+func (server *Server) saveAttachment(ctx context.Context, q *sqlc.Queries, ticketID int32, commentID pgtype.Int4, uploadedBy int32, filename string, content []byte) (sqlc.Attachment, error) {
+	if len(content) > MaxAttachmentSize {
+		return sqlc.Attachment{}, AttachmentTooLargeError{}
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil || !slices.Contains(AllowedAttachmentTypes, contentType) {
+		return sqlc.Attachment{}, UnsupportedAttachmentTypeError{}
+	}
+
+	token, err := secureToken()
+	if err != nil {
+		return sqlc.Attachment{}, err
+	}
+	storageKey := fmt.Sprintf("tickets/%d/%s", ticketID, token)
+	sum := sha256.Sum256(content)
+
+	err = server.storage.Put(ctx, storageKey, bytes.NewReader(content), int64(len(content)), contentType)
+	if err != nil {
+		return sqlc.Attachment{}, err
+	}
+
+	filename = truncateFilename(filepath.Base(filename))
+	attachment, err := q.CreateAttachment(ctx, sqlc.CreateAttachmentParams{
+		TicketID:    ticketID,
+		CommentID:   commentID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Sha256:      hex.EncodeToString(sum[:]),
+		StorageKey:  storageKey,
+		UploadedBy:  uploadedBy,
+	})
+	if err != nil {
+		server.storage.Delete(context.Background(), storageKey)
+		return sqlc.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// truncateFilename keeps the last 255 characters of filename, so the
+// extension survives, without splitting a multi-byte character.
+func truncateFilename(filename string) string {
+	runes := []rune(filename)
+	if len(runes) > 255 {
+		return string(runes[len(runes)-255:])
+	}
+	return filename
+}
+
+func (server *Server) downloadAttachment(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "attachment not found"})
+		return
+	}
+
+	attachmentId, err := strconv.ParseInt(c.Param("attachmentId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "attachment not found"})
+		return
+	}
+
+	attachment, err := server.db.Queries().GetAttachmentByID(c, int32(attachmentId))
+	if err != nil || attachment.TicketID != int32(ticketId) {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "attachment not found"})
+		return
+	}
+
+	if attachment.CommentID.Valid && !canViewInternalComments(user) {
+		comment, err := server.db.Queries().GetCommentByID(c, attachment.CommentID.Int32)
+		if err != nil || comment.Visibility == sqlc.CommentVisibilityInternal {
+			c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "attachment not found"})
+			return
+		}
+	}
+
+	content, err := server.storage.Get(c.Request.Context(), attachment.StorageKey)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "attachment not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get attachment"})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+type AttachmentNotFoundError struct{}
+
+func (e AttachmentNotFoundError) Error() string {
+	return "attachment not found"
+}
+
+func (server *Server) deleteAttachment(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "attachment not found"})
+		return
+	}
+
+	attachmentId, err := strconv.ParseInt(c.Param("attachmentId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "attachment not found"})
+		return
+	}
+
+	var storageKey string
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		attachment, err := qtx.GetAttachmentByID(ctx, int32(attachmentId))
+		if err != nil || attachment.TicketID != int32(ticketId) {
+			return AttachmentNotFoundError{}
+		}
+
+		if attachment.UploadedBy != user.ID && user.Role != "admin" {
+			return PermissionDeniedError{Message: "only admins and the uploader can delete attachments"}
+		}
+
+		storageKey = attachment.StorageKey
+		return qtx.DeleteAttachment(ctx, attachment.ID)
+	})
+
+	switch err.(type) {
+	case nil:
+		server.storage.Delete(c.Request.Context(), storageKey)
+		c.Status(http.StatusNoContent)
+	case AttachmentNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "attachment not found"})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete attachment"})
+	}
+}
@@ -0,0 +1,164 @@
+package api_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/storage"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Attachments(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	t.Run("success: upload and download", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte("2024-05-01 12:00:00 ERROR connection refused")
+		var res api.CreateAttachmentResponse
+		httpRes, err := sdk.CreateTicketAttachment(ticketRes.Data.ID, "server.log", bytes.NewReader(content), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.Equal(t, "server.log", res.Data.Filename)
+		require.Equal(t, "text/plain", res.Data.ContentType)
+		require.Equal(t, int64(len(content)), res.Data.Size)
+		sum := sha256.Sum256(content)
+		require.Equal(t, hex.EncodeToString(sum[:]), res.Data.SHA256)
+		require.Equal(t, setup.Res().Data.ID, res.Data.UploadedBy.ID)
+
+		httpRes, err = sdk.DownloadAttachment(ticketRes.Data.ID, res.Data.ID)
+		require.NoError(t, err, "error making request")
+		defer httpRes.Body.Close()
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Equal(t, `attachment; filename=server.log`, httpRes.Header.Get("Content-Disposition"))
+		b, err := io.ReadAll(httpRes.Body)
+		require.NoError(t, err, "error reading body")
+		require.Equal(t, content, b)
+	})
+
+	t.Run("success: upload to a comment", func(t *testing.T) {
+		t.Parallel()
+
+		var commentRes api.CreateCommentResponse
+		_, err := sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			Content: gofakeit.Sentence(10),
+		}, &commentRes)
+		require.NoError(t, err, "error creating comment")
+
+		var res api.CreateAttachmentResponse
+		httpRes, err := sdk.CreateCommentAttachment(ticketRes.Data.ID, commentRes.Data.ID, "notes.txt", strings.NewReader("steps to reproduce"), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.Equal(t, commentRes.Data.ID, res.Data.CommentID)
+
+		var listRes api.AttachmentsResponse
+		_, err = sdk.Attachments(ticketRes.Data.ID, &listRes)
+		require.NoError(t, err, "error making request")
+		require.NotEmpty(t, listRes.Data)
+	})
+
+	t.Run("error: unsupported type", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateAttachmentResponse
+		httpRes, err := sdk.CreateTicketAttachment(ticketRes.Data.ID, "setup.exe", bytes.NewReader([]byte{0x4d, 0x5a, 0x90, 0x00, 0x03, 0x00}), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusUnsupportedMediaType, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "file", "mimetype")
+	})
+
+	t.Run("error: too large", func(t *testing.T) {
+		t.Parallel()
+
+		content := bytes.Repeat([]byte("a"), api.MaxAttachmentSize+1)
+		var res api.CreateAttachmentResponse
+		httpRes, err := sdk.CreateTicketAttachment(ticketRes.Data.ID, "big.txt", bytes.NewReader(content), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusRequestEntityTooLarge, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "file", "max")
+	})
+
+	t.Run("success: long names are cut by characters", func(t *testing.T) {
+		t.Parallel()
+
+		filename := strings.Repeat("é", 300) + ".txt"
+		var res api.CreateAttachmentResponse
+		httpRes, err := sdk.CreateTicketAttachment(ticketRes.Data.ID, filename, strings.NewReader("long name"), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.True(t, utf8.ValidString(res.Data.Filename))
+		require.Equal(t, 255, utf8.RuneCountInString(res.Data.Filename))
+		require.True(t, strings.HasSuffix(res.Data.Filename, ".txt"))
+	})
+}
+
+func TestDeleteTicket_Attachments(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tEnv := testutil.NewEnv(t)
+	tEnv.Server().SetStorage(storage.NewLocalStorage(dir))
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	var commentRes api.CreateCommentResponse
+	_, err = sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: gofakeit.Sentence(10),
+	}, &commentRes)
+	require.NoError(t, err, "error creating comment")
+
+	var attachmentRes api.CreateAttachmentResponse
+	_, err = sdk.CreateTicketAttachment(ticketRes.Data.ID, "server.log", strings.NewReader("connection refused"), &attachmentRes)
+	require.NoError(t, err, "error creating attachment")
+	_, err = sdk.CreateCommentAttachment(ticketRes.Data.ID, commentRes.Data.ID, "notes.txt", strings.NewReader("steps to reproduce"), &attachmentRes)
+	require.NoError(t, err, "error creating attachment")
+
+	files := func() int {
+		count := 0
+		err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				count++
+			}
+			return err
+		})
+		require.NoError(t, err, "error reading storage")
+		return count
+	}
+	require.Equal(t, 2, files())
+
+	httpRes, err := sdk.DeleteTicket(ticketRes.Data.ID)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+	require.Zero(t, files())
+}
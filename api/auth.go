@@ -21,6 +21,16 @@ const TokenHeader = "OPENTICKET-TOKEN"
 const TokenCookie = "openticket-token"
 const userCtxKey = "user"
 
+const DefaultSessionMaxAge = 30 * 24 * time.Hour
+
+// SetSessionMaxAge sets how long sessions created on login are valid.
+func (server *Server) SetSessionMaxAge(maxAge time.Duration) {
+	if maxAge == 0 {
+		maxAge = DefaultSessionMaxAge
+	}
+	server.sessionMaxAge = maxAge
+}
+
 func (server *Server) AuthRequired(c *gin.Context) {
 	user, err := server.AuthUser(c)
 
@@ -117,7 +127,7 @@ func (server *Server) login(c *gin.Context) {
 	}
 	sum := sha256.Sum256([]byte(token))
 	tokenHash := base64.URLEncoding.EncodeToString(sum[:])
-	maxAge := time.Hour * 24 * 30
+	maxAge := server.sessionMaxAge
 	tokenExpiration := time.Now().Add(maxAge)
 	_, err = server.db.Queries().CreateSession(ctx, sqlc.CreateSessionParams{
 		UserID:    user.ID,
@@ -132,7 +142,7 @@ func (server *Server) login(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie(TokenCookie, token, int(maxAge), "/", "", false, true)
+	c.SetCookie(TokenCookie, token, int(maxAge.Seconds()), "/", "", false, true)
 	c.JSON(200, LoginResponse{
 		Data: LoginData{
 			User: User{
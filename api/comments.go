@@ -3,49 +3,134 @@ package api
 import (
 	"context"
 	"net/http"
+	"slices"
 	"strconv"
 
 	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/BrunoQuaresma/openticket/api/markdown"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type CreateCommentRequest struct {
-	Content string `json:"content" validate:"required,min=10"`
-	ReplyTo int32  `json:"reply_to,omitempty" validate:"number,omitempty"`
+	Content    string `json:"content,omitempty" validate:"required_without=TemplateID,excluded_with=TemplateID,omitempty,min=10"`
+	ReplyTo    int32  `json:"reply_to,omitempty" validate:"number,omitempty"`
+	Visibility string `json:"visibility,omitempty" validate:"omitempty,oneof=public internal"`
+	// TemplateID renders a reply template against the ticket as the
+	// comment's content.
+	TemplateID int32 `json:"template_id,omitempty"`
 }
 
 type Comment struct {
-	ID        int32  `json:"id"`
-	Content   string `json:"content"`
-	CreatedAt string `json:"created_at"`
-	ReplyTo   int32  `json:"reply_to,omitempty"`
-	CreatedBy User   `json:"created_by"`
+	ID         int32      `json:"id"`
+	Content    string     `json:"content"`
+	HTML       string     `json:"html,omitempty"`
+	CreatedAt  string     `json:"created_at"`
+	ReplyTo    int32      `json:"reply_to,omitempty"`
+	Visibility string     `json:"visibility"`
+	Deleted    bool       `json:"deleted,omitempty"`
+	EditedAt   string     `json:"edited_at,omitempty"`
+	Revisions  int64      `json:"revisions"`
+	CreatedBy  User       `json:"created_by"`
+	Mentions   []User     `json:"mentions"`
+	Reactions  []Reaction `json:"reactions,omitempty"`
+	Replies    []Comment  `json:"replies,omitempty"`
+}
+
+// Internal comments are triage notes for staff. Requesters only see public
+// comments.
+func canViewInternalComments(user *sqlc.User) bool {
+	return user.Role == sqlc.RoleAdmin || user.Role == sqlc.RoleMember
 }
 
 type CommentsResponse = Response[[]Comment]
 
+const (
+	CommentsFlatFormat = "flat"
+	CommentsTreeFormat = "tree"
+)
+
+const CommentsRenderHTML = "html"
+
 func (server *Server) comments(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
 	ticketId, err := strconv.ParseUint(c.Param("ticketId"), 10, 32)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
 		return
 	}
 
-	comments, err := server.db.Queries().GetCommentsByTicketID(c, int32(ticketId))
+	format := c.DefaultQuery("format", CommentsFlatFormat)
+	if format != CommentsFlatFormat && format != CommentsTreeFormat {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Errors: []ValidationError{{Field: "format", Validator: "oneof"}},
+		})
+		return
+	}
+
+	render := c.Query("render")
+	if render != "" && render != CommentsRenderHTML {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Errors: []ValidationError{{Field: "render", Validator: "oneof"}},
+		})
+		return
+	}
+
+	comments, err := server.db.Queries().GetCommentsByTicketID(c, sqlc.GetCommentsByTicketIDParams{
+		TicketID:        int32(ticketId),
+		IncludeInternal: canViewInternalComments(user),
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get comments"})
+		return
+	}
+
+	commentIds := make([]int32, len(comments))
+	for i, comment := range comments {
+		commentIds[i] = comment.ID
+	}
+	mentionRows, err := server.db.Queries().GetMentionsByCommentIDs(c, commentIds)
 	if err != nil && err != pgx.ErrNoRows {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get comments"})
 		return
 	}
+	mentions := make(map[int32][]sqlc.User)
+	for _, mention := range mentionRows {
+		mentions[mention.CommentID] = append(mentions[mention.CommentID], mention.User)
+	}
+	reactions, err := commentReactions(c, server.db.Queries(), user.ID, commentIds...)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get comments"})
+		return
+	}
 
 	var commentsResponse []Comment
 	for _, comment := range comments {
+		var editedAt string
+		if comment.EditedAt.Valid {
+			editedAt = comment.EditedAt.Time.UTC().String()
+		}
+		var html string
+		if render == CommentsRenderHTML && !comment.DeletedAt.Valid {
+			usernames := make([]string, len(mentions[comment.ID]))
+			for i, mentioned := range mentions[comment.ID] {
+				usernames[i] = mentioned.Username
+			}
+			html = markdown.Render(comment.Content, usernames...)
+		}
 		commentsResponse = append(commentsResponse, Comment{
-			ID:        comment.ID,
-			Content:   comment.Content,
-			CreatedAt: comment.CreatedAt.Time.UTC().String(),
-			ReplyTo:   comment.ReplyTo.Int32,
+			ID:         comment.ID,
+			Content:    comment.Content,
+			HTML:       html,
+			CreatedAt:  comment.CreatedAt.Time.UTC().String(),
+			ReplyTo:    comment.ReplyTo.Int32,
+			Visibility: string(comment.Visibility),
+			Deleted:    comment.DeletedAt.Valid,
+			EditedAt:   editedAt,
+			Revisions:  comment.Revisions,
 			CreatedBy: User{
 				ID:       comment.UserID,
 				Username: comment.User.Username,
@@ -53,12 +138,47 @@ func (server *Server) comments(c *gin.Context) {
 				Email:    comment.User.Email,
 				Role:     string(comment.User.Role),
 			},
+			Mentions:  mentionsResponse(mentions[comment.ID]),
+			Reactions: reactions[comment.ID],
 		})
 	}
 
+	if format == CommentsTreeFormat {
+		commentsResponse = commentTree(commentsResponse)
+	}
+
 	c.JSON(http.StatusOK, CommentsResponse{Data: commentsResponse})
 }
 
+// commentTree nests replies under their parent comment. Replies whose parent
+// is not in the list are kept at the root so they are never dropped.
+func commentTree(comments []Comment) []Comment {
+	ids := make(map[int32]bool, len(comments))
+	for _, comment := range comments {
+		ids[comment.ID] = true
+	}
+
+	var roots []Comment
+	replies := make(map[int32][]Comment)
+	for _, comment := range comments {
+		if comment.ReplyTo != 0 && ids[comment.ReplyTo] {
+			replies[comment.ReplyTo] = append(replies[comment.ReplyTo], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	var nest func(comments []Comment) []Comment
+	nest = func(comments []Comment) []Comment {
+		for i := range comments {
+			comments[i].Replies = nest(replies[comments[i].ID])
+		}
+		return comments
+	}
+
+	return nest(roots)
+}
+
 type CreateCommentResponse = Response[Comment]
 
 func (server *Server) createComment(c *gin.Context) {
@@ -72,25 +192,90 @@ func (server *Server) createComment(c *gin.Context) {
 
 	var req CreateCommentRequest
 	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
 
-	ctx := c.Request.Context()
-	newComment, err := server.db.Queries().CreateComment(ctx, sqlc.CreateCommentParams{
-		Content:  req.Content,
-		TicketID: int32(ticketId),
-		UserID:   user.ID,
-		ReplyTo:  pgtype.Int4{Int32: req.ReplyTo, Valid: req.ReplyTo != 0},
+	var (
+		newComment sqlc.Comment
+		mentioned  []sqlc.User
+	)
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		ticket, err := qtx.GetTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return TicketNotFoundError{}
+		}
+
+		if req.TemplateID != 0 {
+			template, err := qtx.GetReplyTemplateByID(ctx, req.TemplateID)
+			if err != nil || !canUseReplyTemplate(user, template) {
+				return ReplyTemplateNotFoundError{}
+			}
+			req.Content = renderReplyTemplate(template.Content, ticket, user)
+
+			// The rendered content follows the rules of CreateCommentRequest.Content.
+			err = server.validate.Var(req.Content, "required,min=10")
+			if err != nil {
+				return InvalidRenderedTemplateError{Validator: err.(validator.ValidationErrors)[0].Tag()}
+			}
+		}
+
+		newComment, mentioned, err = insertComment(ctx, qtx, user, ticket.ID, req)
+		return err
 	})
-	if err != nil {
+
+	switch err.(type) {
+	case nil:
+	case TicketNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	case InvalidReplyToError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "reply_to", Validator: "exists"},
+			},
+		})
+		return
+	case DeletedReplyToError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "reply_to", Validator: "not_deleted"},
+			},
+		})
+		return
+	case ReplyTemplateNotFoundError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "template_id", Validator: "exists"},
+			},
+		})
+		return
+	case InvalidRenderedTemplateError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "template_id", Validator: err.(InvalidRenderedTemplateError).Validator},
+			},
+		})
+		return
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+		return
+	default:
 		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create comment"})
 		return
 	}
 
 	c.JSON(http.StatusCreated, CreateCommentResponse{
 		Data: Comment{
-			ID:        newComment.ID,
-			Content:   newComment.Content,
-			CreatedAt: newComment.CreatedAt.Time.UTC().String(),
-			ReplyTo:   newComment.ReplyTo.Int32,
+			ID:         newComment.ID,
+			Content:    newComment.Content,
+			CreatedAt:  newComment.CreatedAt.Time.UTC().String(),
+			ReplyTo:    newComment.ReplyTo.Int32,
+			Visibility: string(newComment.Visibility),
 			CreatedBy: User{
 				ID:       user.ID,
 				Username: user.Username,
@@ -98,40 +283,165 @@ func (server *Server) createComment(c *gin.Context) {
 				Email:    user.Email,
 				Role:     string(user.Role),
 			},
+			Mentions: mentionsResponse(mentioned),
 		},
 	})
 }
 
+// insertComment creates a comment on a ticket, records its mentions and
+// notifies the ticket's watchers.
+func insertComment(ctx context.Context, qtx *sqlc.Queries, user *sqlc.User, ticketID int32, req CreateCommentRequest) (sqlc.Comment, []sqlc.User, error) {
+	if req.Visibility == string(sqlc.CommentVisibilityInternal) && !canViewInternalComments(user) {
+		return sqlc.Comment{}, nil, PermissionDeniedError{Message: "only admins and members can write internal comments"}
+	}
+
+	if req.ReplyTo != 0 {
+		parent, err := qtx.GetCommentByID(ctx, req.ReplyTo)
+		if err != nil || parent.TicketID != ticketID {
+			return sqlc.Comment{}, nil, InvalidReplyToError{}
+		}
+		if parent.Visibility == sqlc.CommentVisibilityInternal && !canViewInternalComments(user) {
+			return sqlc.Comment{}, nil, InvalidReplyToError{}
+		}
+		if parent.DeletedAt.Valid {
+			return sqlc.Comment{}, nil, DeletedReplyToError{}
+		}
+	}
+
+	newComment, err := qtx.CreateComment(ctx, sqlc.CreateCommentParams{
+		Content:  req.Content,
+		TicketID: ticketID,
+		UserID:   user.ID,
+		ReplyTo:  pgtype.Int4{Int32: req.ReplyTo, Valid: req.ReplyTo != 0},
+		Visibility: sqlc.NullCommentVisibility{
+			CommentVisibility: sqlc.CommentVisibility(req.Visibility),
+			Valid:             req.Visibility != "",
+		},
+	})
+	if err != nil {
+		return sqlc.Comment{}, nil, err
+	}
+
+	err = watch(ctx, qtx, ticketID, user.ID)
+	if err != nil {
+		return sqlc.Comment{}, nil, err
+	}
+
+	mentioned, err := saveMentions(ctx, qtx, newComment, user)
+	if err != nil {
+		return sqlc.Comment{}, nil, err
+	}
+
+	err = publishEvent(ctx, qtx, EventCommentCreated, user, commentEventData(newComment, *user))
+	if err != nil {
+		return sqlc.Comment{}, nil, err
+	}
+
+	// Mentioned users were already notified about this comment.
+	err = notifyWatchers(ctx, qtx, NotificationEvent{
+		Type:      sqlc.NotificationTypeComment,
+		TicketID:  ticketID,
+		CommentID: pgtype.Int4{Int32: newComment.ID, Valid: true},
+		ActorID:   user.ID,
+	}, func(watcher sqlc.User) bool {
+		if newComment.Visibility == sqlc.CommentVisibilityInternal && !canViewInternalComments(&watcher) {
+			return false
+		}
+		return !slices.ContainsFunc(mentioned, func(u sqlc.User) bool { return u.ID == watcher.ID })
+	})
+	return newComment, mentioned, err
+}
+
 type CommentNotFoundError struct{}
 
 func (e CommentNotFoundError) Error() string {
 	return "comment not found"
 }
 
+type InvalidReplyToError struct{}
+
+func (e InvalidReplyToError) Error() string {
+	return "reply_to must be a comment of the same ticket"
+}
+
+type DeletedReplyToError struct{}
+
+func (e DeletedReplyToError) Error() string {
+	return "can't reply to a deleted comment"
+}
+
 func (server *Server) deleteComment(c *gin.Context) {
 	user := server.AuthUserFromContext(c)
 
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
 	commentId, err := strconv.ParseInt(c.Param("commentId"), 10, 32)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
 		return
 	}
 
+	var storageKeys []string
 	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
 		comment, err := qtx.GetCommentByID(ctx, int32(commentId))
-		if err != nil {
+		if err != nil || comment.TicketID != int32(ticketId) || comment.DeletedAt.Valid {
 			return CommentNotFoundError{}
 		}
 
-		if comment.UserID == user.ID || user.Role == "admin" {
-			return qtx.DeleteComment(ctx, int32(commentId))
+		if comment.UserID != user.ID && user.Role != "admin" {
+			return PermissionDeniedError{Message: "only admins and the comment's author can delete comments"}
 		}
 
-		return PermissionDeniedError{Message: "only admins and the comment's author can delete comments"}
+		// The stored files are removed once the transaction is committed.
+		storageKeys, err = qtx.DeleteCommentAttachments(ctx, pgtype.Int4{Int32: comment.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+
+		// Comments with replies are kept as tombstones so the thread stays
+		// intact and the reply_to foreign key isn't violated. Nothing but the
+		// tombstone itself is left of them.
+		replies, err := qtx.CountCommentReplies(ctx, pgtype.Int4{Int32: comment.ID, Valid: true})
+		if err != nil {
+			return err
+		}
+		if replies > 0 {
+			err = qtx.DeleteCommentRevisions(ctx, comment.ID)
+			if err != nil {
+				return err
+			}
+			err = qtx.DeleteCommentMentions(ctx, comment.ID)
+			if err != nil {
+				return err
+			}
+			err = qtx.DeleteCommentReactions(ctx, comment.ID)
+			if err != nil {
+				return err
+			}
+			err = qtx.TombstoneComment(ctx, comment.ID)
+		} else {
+			err = qtx.DeleteComment(ctx, comment.ID)
+		}
+		if err != nil {
+			return err
+		}
+
+		author, err := qtx.GetUserByID(ctx, comment.UserID)
+		if err != nil {
+			return err
+		}
+		return publishEvent(ctx, qtx, EventCommentDeleted, user, commentEventData(comment, author))
 	})
 
 	switch err.(type) {
 	case nil:
+		for _, key := range storageKeys {
+			server.storage.Delete(c.Request.Context(), key)
+		}
 		c.Status(http.StatusNoContent)
 	case CommentNotFoundError:
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
@@ -151,6 +461,12 @@ type PatchCommentResponse = Response[Comment]
 func (server *Server) patchComment(c *gin.Context) {
 	user := server.AuthUserFromContext(c)
 
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
 	commentId, err := strconv.ParseInt(c.Param("commentId"), 10, 32)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
@@ -159,18 +475,37 @@ func (server *Server) patchComment(c *gin.Context) {
 
 	var req PatchCommentRequest
 	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
 
 	var (
 		updatedComment sqlc.Comment
 		commentOwner   sqlc.User
+		revisions      int64
+		mentioned      []sqlc.User
 	)
 	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
 		comment, err := qtx.GetCommentByID(ctx, int32(commentId))
-		if err != nil {
+		if err != nil || comment.TicketID != int32(ticketId) || comment.DeletedAt.Valid {
 			return CommentNotFoundError{}
 		}
 
-		if comment.UserID == user.ID || user.Role == "admin" {
+		if comment.UserID != user.ID && user.Role != "admin" {
+			return PermissionDeniedError{Message: "only the comment's author or admins can edit comments"}
+		}
+
+		updatedComment = comment
+		if req.Content != comment.Content {
+			_, err = qtx.CreateCommentRevision(ctx, sqlc.CreateCommentRevisionParams{
+				CommentID: comment.ID,
+				Content:   comment.Content,
+				EditedBy:  user.ID,
+			})
+			if err != nil {
+				return err
+			}
+
 			updatedComment, err = qtx.UpdateCommentByID(ctx, sqlc.UpdateCommentByIDParams{
 				ID:      comment.ID,
 				Content: req.Content,
@@ -178,21 +513,44 @@ func (server *Server) patchComment(c *gin.Context) {
 			if err != nil {
 				return err
 			}
-			commentOwner, err = qtx.GetUserByID(ctx, comment.UserID)
+		}
+
+		mentioned, err = saveMentions(ctx, qtx, updatedComment, user)
+		if err != nil {
+			return err
+		}
+
+		revisions, err = qtx.CountCommentRevisions(ctx, comment.ID)
+		if err != nil {
 			return err
 		}
 
-		return PermissionDeniedError{Message: "only the comment's author or admins can edit comments"}
+		commentOwner, err = qtx.GetUserByID(ctx, comment.UserID)
+		if err != nil {
+			return err
+		}
+
+		if updatedComment.Content == comment.Content {
+			return nil
+		}
+		return publishEvent(ctx, qtx, EventCommentUpdated, user, commentEventData(updatedComment, commentOwner))
 	})
 
 	switch err.(type) {
 	case nil:
+		var editedAt string
+		if updatedComment.EditedAt.Valid {
+			editedAt = updatedComment.EditedAt.Time.UTC().String()
+		}
 		c.JSON(http.StatusOK, PatchCommentResponse{
 			Data: Comment{
-				ID:        updatedComment.ID,
-				Content:   updatedComment.Content,
-				CreatedAt: updatedComment.CreatedAt.Time.UTC().String(),
-				ReplyTo:   updatedComment.ReplyTo.Int32,
+				ID:         updatedComment.ID,
+				Content:    updatedComment.Content,
+				CreatedAt:  updatedComment.CreatedAt.Time.UTC().String(),
+				ReplyTo:    updatedComment.ReplyTo.Int32,
+				Visibility: string(updatedComment.Visibility),
+				EditedAt:   editedAt,
+				Revisions:  revisions,
 				CreatedBy: User{
 					ID:       commentOwner.ID,
 					Username: commentOwner.Username,
@@ -200,6 +558,7 @@ func (server *Server) patchComment(c *gin.Context) {
 					Email:    commentOwner.Email,
 					Role:     string(commentOwner.Role),
 				},
+				Mentions: mentionsResponse(mentioned),
 			},
 		})
 	case CommentNotFoundError:
@@ -210,3 +569,71 @@ func (server *Server) patchComment(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update comment"})
 	}
 }
+
+type CommentRevision struct {
+	ID        int32  `json:"id"`
+	CommentID int32  `json:"comment_id"`
+	Content   string `json:"content"`
+	EditedBy  User   `json:"edited_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CommentRevisionsResponse = Response[[]CommentRevision]
+
+func (server *Server) commentRevisions(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
+	commentId, err := strconv.ParseInt(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
+	var revisionRows []sqlc.GetCommentRevisionsRow
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		comment, err := qtx.GetCommentByID(ctx, int32(commentId))
+		if err != nil || comment.TicketID != int32(ticketId) {
+			return CommentNotFoundError{}
+		}
+
+		if comment.UserID != user.ID && user.Role != "admin" {
+			return PermissionDeniedError{Message: "only the comment's author or admins can see its revisions"}
+		}
+
+		revisionRows, err = qtx.GetCommentRevisions(ctx, comment.ID)
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		revisions := make([]CommentRevision, len(revisionRows))
+		for i, revision := range revisionRows {
+			revisions[i] = CommentRevision{
+				ID:        revision.ID,
+				CommentID: revision.CommentID,
+				Content:   revision.Content,
+				CreatedAt: revision.CreatedAt.Time.UTC().String(),
+				EditedBy: User{
+					ID:       revision.User.ID,
+					Username: revision.User.Username,
+					Name:     revision.User.Name,
+					Email:    revision.User.Email,
+					Role:     string(revision.User.Role),
+				},
+			}
+		}
+		c.JSON(http.StatusOK, CommentRevisionsResponse{Data: revisions})
+	case CommentNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get comment revisions"})
+	}
+}
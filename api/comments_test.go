@@ -2,6 +2,8 @@ package api_test
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/BrunoQuaresma/openticket/api"
@@ -18,10 +20,12 @@ func TestCreateComment_Success(t *testing.T) {
 	setup := tEnv.Setup()
 	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
 
+	label := gofakeit.HackerAbbreviation()
+	sdk.CreateLabel(api.CreateLabelRequest{Name: label}, nil)
 	ticketReq := api.CreateTicketRequest{
 		Title:       gofakeit.JobTitle(),
 		Description: gofakeit.Sentence(10),
-		Labels:      []string{gofakeit.HackerAbbreviation()},
+		Labels:      []string{label},
 	}
 	var ticketRes api.CreateTicketResponse
 	httpRes, err := sdk.CreateTicket(ticketReq, &ticketRes)
@@ -47,10 +51,12 @@ func TestDeleteComment_Success(t *testing.T) {
 	setup := tEnv.Setup()
 	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
 
+	label := gofakeit.HackerAbbreviation()
+	sdk.CreateLabel(api.CreateLabelRequest{Name: label}, nil)
 	ticketReq := api.CreateTicketRequest{
 		Title:       gofakeit.JobTitle(),
 		Description: gofakeit.Sentence(10),
-		Labels:      []string{gofakeit.HackerAbbreviation()},
+		Labels:      []string{label},
 	}
 	var ticketRes api.CreateTicketResponse
 	httpRes, err := sdk.CreateTicket(ticketReq, &ticketRes)
@@ -78,10 +84,12 @@ func TestDeleteComment_FailWhenUserIsNotAdminOrOwner(t *testing.T) {
 	setup := tEnv.Setup()
 	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
 
+	label := gofakeit.HackerAbbreviation()
+	sdk.CreateLabel(api.CreateLabelRequest{Name: label}, nil)
 	ticketReq := api.CreateTicketRequest{
 		Title:       gofakeit.JobTitle(),
 		Description: gofakeit.Sentence(10),
-		Labels:      []string{gofakeit.HackerAbbreviation()},
+		Labels:      []string{label},
 	}
 	var ticketRes api.CreateTicketResponse
 	httpRes, err := sdk.CreateTicket(ticketReq, &ticketRes)
@@ -112,10 +120,12 @@ func TestPatchComment_Success(t *testing.T) {
 	setup := tEnv.Setup()
 	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
 
+	label := gofakeit.HackerAbbreviation()
+	sdk.CreateLabel(api.CreateLabelRequest{Name: label}, nil)
 	ticketReq := api.CreateTicketRequest{
 		Title:       gofakeit.JobTitle(),
 		Description: gofakeit.Sentence(10),
-		Labels:      []string{gofakeit.HackerAbbreviation()},
+		Labels:      []string{label},
 	}
 	var ticketRes api.CreateTicketResponse
 	httpRes, err := sdk.CreateTicket(ticketReq, &ticketRes)
@@ -149,10 +159,12 @@ func TestPatchComment_FailWhenUserIsNotAdminOrOwner(t *testing.T) {
 	setup := tEnv.Setup()
 	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
 
+	label := gofakeit.HackerAbbreviation()
+	sdk.CreateLabel(api.CreateLabelRequest{Name: label}, nil)
 	ticketReq := api.CreateTicketRequest{
 		Title:       gofakeit.JobTitle(),
 		Description: gofakeit.Sentence(10),
-		Labels:      []string{gofakeit.HackerAbbreviation()},
+		Labels:      []string{label},
 	}
 	var ticketRes api.CreateTicketResponse
 	httpRes, err := sdk.CreateTicket(ticketReq, &ticketRes)
@@ -179,3 +191,497 @@ func TestPatchComment_FailWhenUserIsNotAdminOrOwner(t *testing.T) {
 	require.NoError(t, err, "error making request")
 	require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
 }
+
+func TestComments_OtherTicket(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	createTicket := func() api.Ticket {
+		var res api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &res)
+		require.NoError(t, err, "error creating ticket")
+		return res.Data
+	}
+	ticket := createTicket()
+	other := createTicket()
+
+	content := gofakeit.Sentence(10)
+	var commentRes api.CreateCommentResponse
+	_, err := sdk.CreateComment(ticket.ID, api.CreateCommentRequest{Content: content}, &commentRes)
+	require.NoError(t, err, "error creating comment")
+
+	var patchRes api.PatchCommentResponse
+	httpRes, err := sdk.PatchComment(other.ID, commentRes.Data.ID, api.PatchCommentRequest{
+		Content: gofakeit.Sentence(10),
+	}, &patchRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+
+	httpRes, err = sdk.DeleteComment(other.ID, commentRes.Data.ID)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+
+	var commentsRes api.CommentsResponse
+	_, err = sdk.Comments(ticket.ID, &commentsRes, nil)
+	require.NoError(t, err, "error getting comments")
+	require.Len(t, commentsRes.Data, 2)
+	require.Equal(t, content, commentsRes.Data[1].Content)
+}
+
+func TestCreateComment_ReplyTo(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	createTicket := func() api.Ticket {
+		var res api.CreateTicketResponse
+		httpRes, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		return res.Data
+	}
+	ticket := createTicket()
+	otherTicket := createTicket()
+
+	var parentRes api.CreateCommentResponse
+	_, err := sdk.CreateComment(ticket.ID, api.CreateCommentRequest{Content: gofakeit.Sentence(10)}, &parentRes)
+	require.NoError(t, err, "error making request")
+
+	t.Run("success: reply to a comment of the same ticket", func(t *testing.T) {
+		var res api.CreateCommentResponse
+		httpRes, err := sdk.CreateComment(ticket.ID, api.CreateCommentRequest{
+			Content: gofakeit.Sentence(10),
+			ReplyTo: parentRes.Data.ID,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.Equal(t, parentRes.Data.ID, res.Data.ReplyTo)
+	})
+
+	t.Run("error: reply to a comment of another ticket", func(t *testing.T) {
+		var res api.CreateCommentResponse
+		httpRes, err := sdk.CreateComment(otherTicket.ID, api.CreateCommentRequest{
+			Content: gofakeit.Sentence(10),
+			ReplyTo: parentRes.Data.ID,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "reply_to", "exists")
+	})
+
+	t.Run("error: reply to a missing comment", func(t *testing.T) {
+		var res api.CreateCommentResponse
+		httpRes, err := sdk.CreateComment(ticket.ID, api.CreateCommentRequest{
+			Content: gofakeit.Sentence(10),
+			ReplyTo: 999999,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "reply_to", "exists")
+	})
+}
+
+func TestComments_Tree(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	createComment := func(replyTo int32) api.Comment {
+		var res api.CreateCommentResponse
+		httpRes, err := sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			Content: gofakeit.Sentence(10),
+			ReplyTo: replyTo,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		return res.Data
+	}
+	parent := createComment(0)
+	reply := createComment(parent.ID)
+	createComment(reply.ID)
+
+	var res api.CommentsResponse
+	httpRes, err := sdk.Comments(ticketRes.Data.ID, &res, &url.Values{"format": []string{"tree"}})
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	// The ticket description and the parent comment.
+	require.Len(t, res.Data, 2)
+	require.Len(t, res.Data[1].Replies, 1)
+	require.Equal(t, reply.ID, res.Data[1].Replies[0].ID)
+	require.Len(t, res.Data[1].Replies[0].Replies, 1)
+
+	t.Run("deleting a comment with replies leaves a tombstone", func(t *testing.T) {
+		httpRes, err := sdk.DeleteComment(ticketRes.Data.ID, parent.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+		var res api.CommentsResponse
+		_, err = sdk.Comments(ticketRes.Data.ID, &res, &url.Values{"format": []string{"tree"}})
+		require.NoError(t, err, "error making request")
+		require.Len(t, res.Data, 2)
+		require.True(t, res.Data[1].Deleted)
+		require.Empty(t, res.Data[1].Content)
+		require.Len(t, res.Data[1].Replies, 1)
+	})
+}
+
+func TestDeleteComment_TombstoneCleanup(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, _ := testutil.NewMember(t, &sdk)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	var parentRes api.CreateCommentResponse
+	_, err = sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: "@" + member.Username + ", the logs are attached.",
+	}, &parentRes)
+	require.NoError(t, err, "error creating comment")
+	require.Len(t, parentRes.Data.Mentions, 1)
+
+	var reactionsRes api.ReactionsResponse
+	_, err = sdk.CreateCommentReaction(ticketRes.Data.ID, parentRes.Data.ID, api.CreateReactionRequest{Emoji: "eyes"}, &reactionsRes)
+	require.NoError(t, err, "error creating reaction")
+
+	var attachmentRes api.CreateAttachmentResponse
+	_, err = sdk.CreateCommentAttachment(ticketRes.Data.ID, parentRes.Data.ID, "logs.txt", strings.NewReader("connection refused"), &attachmentRes)
+	require.NoError(t, err, "error creating attachment")
+
+	var replyRes api.CreateCommentResponse
+	_, err = sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: gofakeit.Sentence(10),
+		ReplyTo: parentRes.Data.ID,
+	}, &replyRes)
+	require.NoError(t, err, "error creating reply")
+
+	httpRes, err := sdk.DeleteComment(ticketRes.Data.ID, parentRes.Data.ID)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+	t.Run("success: tombstone keeps nothing of the comment", func(t *testing.T) {
+		t.Parallel()
+
+		var commentsRes api.CommentsResponse
+		_, err := sdk.Comments(ticketRes.Data.ID, &commentsRes, nil)
+		require.NoError(t, err, "error making request")
+		require.True(t, commentsRes.Data[1].Deleted)
+		require.Empty(t, commentsRes.Data[1].Mentions)
+		require.Empty(t, commentsRes.Data[1].Reactions)
+
+		var attachmentsRes api.AttachmentsResponse
+		_, err = sdk.Attachments(ticketRes.Data.ID, &attachmentsRes)
+		require.NoError(t, err, "error making request")
+		require.Empty(t, attachmentsRes.Data)
+
+		httpRes, err := sdk.DownloadAttachment(ticketRes.Data.ID, attachmentRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		defer httpRes.Body.Close()
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("error: reply to a deleted comment", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateCommentResponse
+		httpRes, err := sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			Content: gofakeit.Sentence(10),
+			ReplyTo: parentRes.Data.ID,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "reply_to", "not_deleted")
+	})
+}
+
+func TestPatchComment_Revisions(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	var commentRes api.CreateCommentResponse
+	originalContent := "We decided to roll back the release."
+	_, err = sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{Content: originalContent}, &commentRes)
+	require.NoError(t, err, "error creating comment")
+	require.Empty(t, commentRes.Data.EditedAt)
+	require.Zero(t, commentRes.Data.Revisions)
+
+	var patchRes api.PatchCommentResponse
+	httpRes, err := sdk.PatchComment(ticketRes.Data.ID, commentRes.Data.ID, api.PatchCommentRequest{
+		Content: "We decided to ship a hotfix instead.",
+	}, &patchRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.NotEmpty(t, patchRes.Data.EditedAt)
+	require.Equal(t, int64(1), patchRes.Data.Revisions)
+
+	t.Run("success: author sees revisions", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CommentRevisionsResponse
+		httpRes, err := sdk.CommentRevisions(ticketRes.Data.ID, commentRes.Data.ID, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, res.Data, 1)
+		require.Equal(t, originalContent, res.Data[0].Content)
+		require.Equal(t, setup.Res().Data.ID, res.Data[0].EditedBy.ID)
+	})
+
+	t.Run("error: invalid edit is not saved", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.PatchCommentResponse
+		httpRes, err := sdk.PatchComment(ticketRes.Data.ID, commentRes.Data.ID, api.PatchCommentRequest{Content: ""}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "content", "required")
+
+		var revisionsRes api.CommentRevisionsResponse
+		_, err = sdk.CommentRevisions(ticketRes.Data.ID, commentRes.Data.ID, &revisionsRes)
+		require.NoError(t, err, "error getting revisions")
+		require.Len(t, revisionsRes.Data, 1)
+
+		var commentsRes api.CommentsResponse
+		_, err = sdk.Comments(ticketRes.Data.ID, &commentsRes, nil)
+		require.NoError(t, err, "error getting comments")
+		for _, comment := range commentsRes.Data {
+			if comment.ID == commentRes.Data.ID {
+				require.Equal(t, "We decided to ship a hotfix instead.", comment.Content)
+			}
+		}
+	})
+
+	t.Run("error: other members can't see revisions", func(t *testing.T) {
+		t.Parallel()
+
+		member, _ := testutil.NewMember(t, &sdk)
+		memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+		var res api.CommentRevisionsResponse
+		httpRes, err := memberSdk.CommentRevisions(ticketRes.Data.ID, commentRes.Data.ID, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+}
+
+func TestComments_InternalVisibility(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	requester := api.CreateUserRequest{
+		Name:     gofakeit.Name(),
+		Username: gofakeit.Username(),
+		Email:    gofakeit.Email(),
+		Password: testutil.FakePassword(),
+		Role:     "requester",
+	}
+	var requesterRes api.CreateUserResponse
+	httpRes, err := sdk.CreateUser(requester, &requesterRes)
+	require.NoError(t, err, "error creating requester")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	requesterSdk := tEnv.AuthSDK(requester.Email, requester.Password)
+
+	var ticketRes api.CreateTicketResponse
+	_, err = sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	var internalRes api.CreateCommentResponse
+	httpRes, err = sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content:    "Customer is on the legacy plan, escalate to billing.",
+		Visibility: "internal",
+	}, &internalRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	require.Equal(t, "internal", internalRes.Data.Visibility)
+
+	t.Run("success: staff see internal comments", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CommentsResponse
+		_, err := sdk.Comments(ticketRes.Data.ID, &res, nil)
+		require.NoError(t, err, "error making request")
+		require.Len(t, res.Data, 2)
+	})
+
+	t.Run("success: requesters only see public comments", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CommentsResponse
+		_, err := requesterSdk.Comments(ticketRes.Data.ID, &res, nil)
+		require.NoError(t, err, "error making request")
+		require.Len(t, res.Data, 1)
+		require.Equal(t, "public", res.Data[0].Visibility)
+	})
+
+	t.Run("error: requesters can't write internal comments", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateCommentResponse
+		httpRes, err := requesterSdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			Content:    "Can I see the internal notes?",
+			Visibility: "internal",
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+
+	t.Run("error: requesters can't reply to internal comments", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateCommentResponse
+		httpRes, err := requesterSdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			Content: "Replying to a note I shouldn't see.",
+			ReplyTo: internalRes.Data.ID,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "reply_to", "exists")
+	})
+}
+
+func TestComments_Mentions(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, memberRes := testutil.NewMember(t, &sdk)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	var commentRes api.CreateCommentResponse
+	httpRes, err := sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: "@" + member.Username + ", can you take a look? cc @nobody-here",
+	}, &commentRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	require.Len(t, commentRes.Data.Mentions, 1)
+	require.Equal(t, memberRes.Data.ID, commentRes.Data.Mentions[0].ID)
+
+	var commentsRes api.CommentsResponse
+	_, err = sdk.Comments(ticketRes.Data.ID, &commentsRes, nil)
+	require.NoError(t, err, "error making request")
+	require.Len(t, commentsRes.Data[1].Mentions, 1)
+	require.Equal(t, member.Username, commentsRes.Data[1].Mentions[0].Username)
+
+	var patchRes api.PatchCommentResponse
+	httpRes, err = sdk.PatchComment(ticketRes.Data.ID, commentRes.Data.ID, api.PatchCommentRequest{
+		Content: "Nevermind, I found the issue myself.",
+	}, &patchRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.Empty(t, patchRes.Data.Mentions)
+}
+
+func TestComments_RenderHTML(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, _ := testutil.NewMember(t, &sdk)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: "- [x] Reproduce <script>alert(1)</script>",
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	var commentRes api.CreateCommentResponse
+	_, err = sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: "Same as #1, ping @" + member.Username,
+	}, &commentRes)
+	require.NoError(t, err, "error creating comment")
+
+	t.Run("success: html is only rendered on request", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CommentsResponse
+		_, err := sdk.Comments(ticketRes.Data.ID, &res, nil)
+		require.NoError(t, err, "error making request")
+		require.Empty(t, res.Data[0].HTML)
+	})
+
+	t.Run("success: render html", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CommentsResponse
+		httpRes, err := sdk.Comments(ticketRes.Data.ID, &res, &url.Values{"render": []string{api.CommentsRenderHTML}})
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, res.Data, 2)
+		require.Equal(t, "- [x] Reproduce <script>alert(1)</script>", res.Data[0].Content)
+		require.Equal(t, "<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> Reproduce </li>\n</ul>", res.Data[0].HTML)
+		require.Contains(t, res.Data[1].HTML, `<a href="/tickets/1" class="ticket-reference" rel="nofollow">#1</a>`)
+		require.Contains(t, res.Data[1].HTML, `<span class="mention" data-username="`+member.Username+`">@`+member.Username+`</span>`)
+	})
+
+	t.Run("error: invalid render", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CommentsResponse
+		httpRes, err := sdk.Comments(ticketRes.Data.ID, &res, &url.Values{"render": []string{"pdf"}})
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "render", "oneof")
+	})
+}
@@ -0,0 +1,382 @@
+// Package config loads the server configuration. Values are layered, each
+// layer overriding the previous one: defaults, a YAML or TOML file and
+// OPENTICKET_* environment variables. Flags are applied on top by the CLI.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const EnvPrefix = "OPENTICKET_"
+
+const (
+	ModeProduction = "production"
+	ModeDev        = "dev"
+
+	StorageLocal = "local"
+	StorageS3    = "s3"
+)
+
+// Redacted replaces secrets when the config is printed.
+const Redacted = "[redacted]"
+
+type Config struct {
+	// Listen is the address the HTTP server listens on, for example ":3000"
+	// or "127.0.0.1:8080".
+	Listen string `yaml:"listen" toml:"listen"`
+	// DataDir keeps the embedded database and local attachments.
+	DataDir  string   `yaml:"data_dir" toml:"data_dir"`
+	Database Database `yaml:"database" toml:"database"`
+	Sessions Sessions `yaml:"sessions" toml:"sessions"`
+	SMTP     SMTP     `yaml:"smtp" toml:"smtp"`
+	Storage  Storage  `yaml:"storage" toml:"storage"`
+	Log      Log      `yaml:"log" toml:"log"`
+}
+
+type Database struct {
+	// URL of an external Postgres database. The embedded database is used
+	// when it is empty and the fields below configure it.
+	URL      string `yaml:"url" toml:"url"`
+	Port     uint32 `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	Name     string `yaml:"name" toml:"name"`
+}
+
+type Sessions struct {
+	MaxAge Duration `yaml:"max_age" toml:"max_age"`
+}
+
+// SMTP configures email notifications. They are disabled when Host is empty.
+type SMTP struct {
+	Host         string   `yaml:"host" toml:"host"`
+	Port         int      `yaml:"port" toml:"port"`
+	Username     string   `yaml:"username" toml:"username"`
+	Password     string   `yaml:"password" toml:"password"`
+	From         string   `yaml:"from" toml:"from"`
+	BaseURL      string   `yaml:"base_url" toml:"base_url"`
+	MaxAttempts  int      `yaml:"max_attempts" toml:"max_attempts"`
+	RetryBackoff Duration `yaml:"retry_backoff" toml:"retry_backoff"`
+}
+
+type Storage struct {
+	// Driver is either "local" or "s3".
+	Driver string `yaml:"driver" toml:"driver"`
+	// Dir is where the local driver keeps attachments. It defaults to the
+	// attachments directory inside DataDir.
+	Dir string `yaml:"dir" toml:"dir"`
+	S3  S3     `yaml:"s3" toml:"s3"`
+}
+
+type S3 struct {
+	Endpoint        string `yaml:"endpoint" toml:"endpoint"`
+	Region          string `yaml:"region" toml:"region"`
+	Bucket          string `yaml:"bucket" toml:"bucket"`
+	AccessKeyID     string `yaml:"access_key_id" toml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key" toml:"secret_access_key"`
+}
+
+type Log struct {
+	// Mode is "production" or "dev". Dev mode logs debug information.
+	Mode string `yaml:"mode" toml:"mode"`
+	// File receives the logs instead of stderr when it is set.
+	File string `yaml:"file" toml:"file"`
+}
+
+// Duration is a time.Duration written as a string like "720h" in config
+// files and environment variables.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func Default() Config {
+	return Config{
+		Listen:  ":3000",
+		DataDir: ".openticket",
+		Database: Database{
+			Port:     5432,
+			Username: "postgres",
+			Password: "postgres",
+			Name:     "postgres",
+		},
+		Sessions: Sessions{
+			MaxAge: Duration(30 * 24 * time.Hour),
+		},
+		SMTP: SMTP{
+			Port: 587,
+		},
+		Storage: Storage{
+			Driver: StorageLocal,
+		},
+		Log: Log{
+			Mode: ModeProduction,
+		},
+	}
+}
+
+// Load returns the default config overridden by the file at path, when it
+// isn't empty, and by the environment.
+func Load(path string) (Config, error) {
+	config := Default()
+
+	if path != "" {
+		err := config.loadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+
+	err := config.loadEnv(os.LookupEnv)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+// loadFile decodes the file at path on top of config. The format is picked
+// from the extension and unknown keys are rejected to catch typos.
+func (config *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(config)
+		// An empty file is a valid config without overrides.
+		if err == io.EOF {
+			err = nil
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(config)
+	default:
+		return fmt.Errorf("unsupported config file %q, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overrides config with environment variables. Their names are the
+// upper-cased keys of the file joined by underscores, for example
+// OPENTICKET_DATABASE_URL or OPENTICKET_STORAGE_S3_BUCKET.
+func (config *Config) loadEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	for _, field := range fields(reflect.ValueOf(config).Elem(), EnvPrefix) {
+		value, ok := lookup(field.env)
+		if !ok {
+			continue
+		}
+		err := setField(field.value, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", field.env, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type field struct {
+	env   string
+	value reflect.Value
+}
+
+func fields(v reflect.Value, prefix string) []field {
+	var result []field
+	for i := 0; i < v.NumField(); i++ {
+		name := prefix + strings.ToUpper(v.Type().Field(i).Tag.Get("yaml"))
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct {
+			result = append(result, fields(value, name+"_")...)
+			continue
+		}
+		result = append(result, field{env: name, value: value})
+	}
+	return result
+}
+
+func setField(v reflect.Value, value string) error {
+	if u, ok := v.Addr().Interface().(interface{ UnmarshalText([]byte) error }); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint32:
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// AttachmentsDir is the directory used by the local storage driver.
+func (config Config) AttachmentsDir() string {
+	if config.Storage.Dir != "" {
+		return config.Storage.Dir
+	}
+	return filepath.Join(config.DataDir, "attachments")
+}
+
+// Validate reports every invalid value of the config at once.
+func (config Config) Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+	}
+
+	_, port, err := net.SplitHostPort(config.Listen)
+	if err != nil {
+		invalid("listen", "must be an address like :3000 or 127.0.0.1:3000")
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		invalid("listen", "invalid port %q", port)
+	}
+
+	if config.Database.URL != "" {
+		u, err := url.Parse(config.Database.URL)
+		if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			invalid("database.url", "must be a postgres:// or postgresql:// URL")
+		}
+	} else {
+		if config.DataDir == "" {
+			invalid("data_dir", "is required for the embedded database")
+		}
+		if config.Database.Port == 0 {
+			invalid("database.port", "is required for the embedded database")
+		}
+		if config.Database.Username == "" {
+			invalid("database.username", "is required for the embedded database")
+		}
+		if config.Database.Name == "" {
+			invalid("database.name", "is required for the embedded database")
+		}
+	}
+
+	if config.Sessions.MaxAge <= 0 {
+		invalid("sessions.max_age", "must be positive")
+	}
+
+	if config.SMTP.Host != "" {
+		if config.SMTP.Port < 1 || config.SMTP.Port > 65535 {
+			invalid("smtp.port", "invalid port %d", config.SMTP.Port)
+		}
+		if _, err := mail.ParseAddress(config.SMTP.From); err != nil {
+			invalid("smtp.from", "must be an email address")
+		}
+		if config.SMTP.BaseURL != "" {
+			if u, err := url.Parse(config.SMTP.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+				invalid("smtp.base_url", "must be an absolute URL")
+			}
+		}
+		if config.SMTP.MaxAttempts < 0 {
+			invalid("smtp.max_attempts", "can't be negative")
+		}
+		if config.SMTP.RetryBackoff < 0 {
+			invalid("smtp.retry_backoff", "can't be negative")
+		}
+	}
+
+	switch config.Storage.Driver {
+	case StorageLocal:
+		if config.AttachmentsDir() == "" {
+			invalid("storage.dir", "is required for local storage")
+		}
+	case StorageS3:
+		s3 := config.Storage.S3
+		if u, err := url.Parse(s3.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			invalid("storage.s3.endpoint", "must be an absolute URL")
+		}
+		for _, required := range [][2]string{
+			{"storage.s3.region", s3.Region},
+			{"storage.s3.bucket", s3.Bucket},
+			{"storage.s3.access_key_id", s3.AccessKeyID},
+			{"storage.s3.secret_access_key", s3.SecretAccessKey},
+		} {
+			if required[1] == "" {
+				invalid(required[0], "is required for s3 storage")
+			}
+		}
+	default:
+		invalid("storage.driver", "must be %q or %q", StorageLocal, StorageS3)
+	}
+
+	if config.Log.Mode != ModeProduction && config.Log.Mode != ModeDev {
+		invalid("log.mode", "must be %q or %q", ModeProduction, ModeDev)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redact returns a copy of the config that is safe to print. Passwords and
+// keys are replaced and the password of the database URL is masked.
+func (config Config) Redact() Config {
+	config.Database.Password = redact(config.Database.Password)
+	config.SMTP.Password = redact(config.SMTP.Password)
+	config.Storage.S3.SecretAccessKey = redact(config.Storage.S3.SecretAccessKey)
+	if u, err := url.Parse(config.Database.URL); err == nil {
+		config.Database.URL = u.Redacted()
+	}
+	return config
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// Marshal encodes the config as "yaml" or "toml".
+func (config Config) Marshal(format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return yaml.Marshal(config)
+	case "toml":
+		return toml.Marshal(config)
+	default:
+		return nil, fmt.Errorf("unsupported format %q, use yaml or toml", format)
+	}
+}
@@ -0,0 +1,123 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BrunoQuaresma/openticket/api/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0644)
+	require.NoError(t, err, "error writing config file")
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("success: defaults", func(t *testing.T) {
+		cfg, err := config.Load("")
+		require.NoError(t, err)
+		require.Equal(t, config.Default(), cfg)
+		require.NoError(t, cfg.Validate())
+		require.Equal(t, filepath.Join(".openticket", "attachments"), cfg.AttachmentsDir())
+	})
+
+	t.Run("success: env overrides yaml", func(t *testing.T) {
+		path := writeFile(t, "openticket.yaml", `
+listen: 127.0.0.1:8080
+database:
+  url: postgres://openticket:secret@db:5432/openticket
+sessions:
+  max_age: 12h
+smtp:
+  host: smtp.example.com
+  from: Openticket <support@example.com>
+`)
+		t.Setenv("OPENTICKET_SMTP_PORT", "2525")
+		t.Setenv("OPENTICKET_SESSIONS_MAX_AGE", "24h")
+
+		cfg, err := config.Load(path)
+		require.NoError(t, err)
+		require.NoError(t, cfg.Validate())
+		require.Equal(t, "127.0.0.1:8080", cfg.Listen)
+		require.Equal(t, "postgres://openticket:secret@db:5432/openticket", cfg.Database.URL)
+		require.Equal(t, config.Duration(24*time.Hour), cfg.Sessions.MaxAge)
+		require.Equal(t, "smtp.example.com", cfg.SMTP.Host)
+		require.Equal(t, 2525, cfg.SMTP.Port)
+	})
+
+	t.Run("success: toml", func(t *testing.T) {
+		path := writeFile(t, "openticket.toml", `
+data_dir = "/var/lib/openticket"
+
+[storage]
+driver = "s3"
+
+[storage.s3]
+endpoint = "http://localhost:9000"
+region = "us-east-1"
+bucket = "attachments"
+access_key_id = "key"
+secret_access_key = "secret"
+`)
+
+		cfg, err := config.Load(path)
+		require.NoError(t, err)
+		require.NoError(t, cfg.Validate())
+		require.Equal(t, "/var/lib/openticket", cfg.DataDir)
+		require.Equal(t, config.StorageS3, cfg.Storage.Driver)
+		require.Equal(t, "secret", cfg.Storage.S3.SecretAccessKey)
+	})
+
+	t.Run("error: unknown key", func(t *testing.T) {
+		path := writeFile(t, "openticket.yaml", "sessions:\n  maxage: 12h\n")
+		_, err := config.Load(path)
+		require.Error(t, err)
+	})
+
+	t.Run("error: invalid env", func(t *testing.T) {
+		t.Setenv("OPENTICKET_DATABASE_PORT", "postgres")
+		_, err := config.Load("")
+		require.ErrorContains(t, err, "OPENTICKET_DATABASE_PORT")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.Listen = "3000"
+	cfg.Sessions.MaxAge = 0
+	cfg.SMTP.Host = "smtp.example.com"
+	cfg.Storage.Driver = config.StorageS3
+	cfg.Log.Mode = "verbose"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, key := range []string{"listen", "sessions.max_age", "smtp.from", "storage.s3.endpoint", "storage.s3.bucket", "log.mode"} {
+		require.ErrorContains(t, err, key+":")
+	}
+}
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Default()
+	cfg.Database.URL = "postgres://openticket:hunter2@db:5432/openticket"
+	cfg.SMTP.Password = "hunter2"
+	cfg.Storage.S3.SecretAccessKey = "hunter2"
+
+	for _, format := range []string{"yaml", "toml"} {
+		out, err := cfg.Redact().Marshal(format)
+		require.NoError(t, err)
+		require.NotContains(t, string(out), "hunter2")
+		require.Contains(t, string(out), config.Redacted)
+	}
+
+	// The config itself is left untouched.
+	require.Equal(t, "hunter2", cfg.SMTP.Password)
+}
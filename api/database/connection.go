@@ -9,6 +9,7 @@ import (
 )
 
 type Connection struct {
+	url     string
 	queries *sqlc.Queries
 	pgConn  *pgxpool.Pool
 }
@@ -20,6 +21,7 @@ func Connect(connStr string) (Connection, error) {
 		return Connection{}, err
 	}
 	return Connection{
+		url:     connStr,
 		pgConn:  pgConn,
 		queries: sqlc.New(pgConn),
 	}, nil
@@ -49,3 +51,28 @@ func (db *Connection) TX(fn txFn) error {
 
 	return tx.Commit(ctx)
 }
+
+// Listen calls fn with the payload of every notification sent to channel
+// until ctx is canceled or the connection fails. A connection is taken out of
+// the pool while listening.
+func (db *Connection) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	poolConn, err := db.pgConn.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn := poolConn.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		fn(notification.Payload)
+	}
+}
@@ -1,17 +1,13 @@
 package database
 
 import (
-	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
-	"path"
 	"path/filepath"
 
 	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
-	migrate "github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 type LocalDatabase struct {
@@ -19,35 +15,55 @@ type LocalDatabase struct {
 	password string
 	database string
 	port     uint32
+	path     string
 	pg       *embeddedpostgres.EmbeddedPostgres
 	logger   io.Writer
 }
 
-func (testDB *LocalDatabase) Start() error {
-	return testDB.pg.Start()
+// SetCredentials changes the user, password and database name. They must be
+// set before Start and only apply when the data directory is initialized.
+func (testDB *LocalDatabase) SetCredentials(username, password, database string) {
+	testDB.username = username
+	testDB.password = password
+	testDB.database = database
 }
 
-func (testDB *LocalDatabase) Migrate() error {
-	appPath := os.Getenv("APP_PATH")
-	m, err := migrate.New("file://"+path.Join(appPath, "api", "database", "migrations"), testDB.URL())
-	if err != nil {
-		testDB.Stop()
-		return errors.New("error creating migration instance: " + err.Error())
-	}
-	err = m.Up()
-	if err != nil && err != migrate.ErrNoChange {
-		testDB.Stop()
-		return errors.New("error running migrations: " + err.Error())
-	}
-	return nil
+func (testDB *LocalDatabase) Start() error {
+	os.MkdirAll(testDB.path, os.ModePerm)
+	runtimePath := filepath.Join(testDB.path, "tmp")
+	dataPath := runtimePath
+	dataPath = filepath.Join(testDB.path, "data")
+
+	testDB.pg = embeddedpostgres.NewDatabase(
+		embeddedpostgres.DefaultConfig().
+			Port(testDB.port).
+			Username(testDB.username).
+			Password(testDB.password).
+			Database(testDB.database).
+			Logger(testDB.logger).
+			RuntimePath(runtimePath).
+			DataPath(dataPath),
+	)
+
+	return testDB.pg.Start()
 }
 
 func (testDB *LocalDatabase) Stop() error {
+	if testDB.pg == nil {
+		return nil
+	}
 	return testDB.pg.Stop()
 }
 
 func (testDB *LocalDatabase) URL() string {
-	return "postgresql://" + testDB.username + ":" + testDB.password + "@localhost:" + fmt.Sprint(testDB.port) + "/" + testDB.database + "?sslmode=disable"
+	u := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(testDB.username, testDB.password),
+		Host:     "localhost:" + fmt.Sprint(testDB.port),
+		Path:     "/" + testDB.database,
+		RawQuery: "sslmode=disable",
+	}
+	return u.String()
 }
 
 func NewLocalDatabase(port uint32, path string, logger io.Writer) *LocalDatabase {
@@ -56,24 +72,9 @@ func NewLocalDatabase(port uint32, path string, logger io.Writer) *LocalDatabase
 		password: "postgres",
 		database: "postgres",
 		port:     port,
+		path:     path,
 		logger:   logger,
 	}
 
-	os.MkdirAll(path, os.ModePerm)
-	runtimePath := filepath.Join(path, "tmp")
-	dataPath := runtimePath
-	dataPath = filepath.Join(path, "data")
-
-	testDB.pg = embeddedpostgres.NewDatabase(
-		embeddedpostgres.DefaultConfig().
-			Port(testDB.port).
-			Username(testDB.username).
-			Password(testDB.password).
-			Database(testDB.database).
-			Logger(testDB.logger).
-			RuntimePath(runtimePath).
-			DataPath(dataPath),
-	)
-
 	return testDB
 }
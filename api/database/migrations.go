@@ -0,0 +1,127 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+
+	migrate "github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+func (db *Connection) migrate() (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return nil, errors.New("error creating migration instance: " + err.Error())
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", source, db.url)
+	if err != nil {
+		return nil, errors.New("error creating migration instance: " + err.Error())
+	}
+	return m, nil
+}
+
+// Migrate applies the migrations embedded in the binary that are not applied
+// to the database yet.
+func (db *Connection) Migrate() error {
+	m, err := db.migrate()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	err = m.Up()
+	if err != nil && err != migrate.ErrNoChange {
+		return errors.New("error running migrations: " + err.Error())
+	}
+	return nil
+}
+
+// MigrateDown rolls back the last steps migrations.
+func (db *Connection) MigrateDown(steps int) error {
+	m, err := db.migrate()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	err = m.Steps(-steps)
+	if err != nil {
+		return errors.New("error rolling back migrations: " + err.Error())
+	}
+	return nil
+}
+
+// ForceMigration sets the schema version without running any migration and
+// clears the dirty flag left by a failed migration. A version of -1 means no
+// migration is applied.
+func (db *Connection) ForceMigration(version int) error {
+	m, err := db.migrate()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	err = m.Force(version)
+	if err != nil {
+		return errors.New("error forcing migration version: " + err.Error())
+	}
+	return nil
+}
+
+type MigrationStatus struct {
+	// Version is the last applied migration, zero when none is applied.
+	Version uint
+	// Dirty is set when the last migration failed halfway.
+	Dirty   bool
+	Pending []uint
+}
+
+func (db *Connection) MigrationStatus() (MigrationStatus, error) {
+	m, err := db.migrate()
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+	defer m.Close()
+
+	var status MigrationStatus
+	status.Version, status.Dirty, err = m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return MigrationStatus{}, errors.New("error getting migration version: " + err.Error())
+	}
+
+	versions, err := migrationVersions()
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+	for _, version := range versions {
+		if version > status.Version {
+			status.Pending = append(status.Pending, version)
+		}
+	}
+	return status, nil
+}
+
+// migrationVersions lists the versions of the embedded migrations in order.
+func migrationVersions() ([]uint, error) {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	var versions []uint
+	version, err := source.First()
+	for err == nil {
+		versions = append(versions, version)
+		version, err = source.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return versions, nil
+}
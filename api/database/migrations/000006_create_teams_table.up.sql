@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS teams (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    created_by INTEGER REFERENCES users (id) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id INTEGER REFERENCES teams (id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS team_assignments (
+    id SERIAL PRIMARY KEY,
+    ticket_id INTEGER REFERENCES tickets (id) ON DELETE CASCADE NOT NULL,
+    team_id INTEGER REFERENCES teams (id) ON DELETE CASCADE NOT NULL,
+    assigned_by INTEGER REFERENCES users (id) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(team_id, ticket_id)
+);
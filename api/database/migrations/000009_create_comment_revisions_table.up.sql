@@ -0,0 +1,9 @@
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id SERIAL PRIMARY KEY,
+    comment_id INTEGER REFERENCES comments (id) ON DELETE CASCADE NOT NULL,
+    content TEXT NOT NULL,
+    edited_by INTEGER REFERENCES users (id) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
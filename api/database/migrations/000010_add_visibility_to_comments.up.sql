@@ -0,0 +1,5 @@
+ALTER TYPE role ADD VALUE IF NOT EXISTS 'requester';
+
+CREATE TYPE comment_visibility AS ENUM ('public', 'internal');
+
+ALTER TABLE comments ADD COLUMN visibility comment_visibility NOT NULL DEFAULT 'public';
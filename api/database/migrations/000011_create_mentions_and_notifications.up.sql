@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id INTEGER REFERENCES comments (id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE TYPE notification_type AS ENUM ('mention');
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    type notification_type NOT NULL,
+    ticket_id INTEGER REFERENCES tickets (id) ON DELETE CASCADE NOT NULL,
+    comment_id INTEGER REFERENCES comments (id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES users (id) ON DELETE CASCADE NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id, created_at DESC);
@@ -0,0 +1,5 @@
+DELETE FROM notifications WHERE type <> 'mention';
+ALTER TYPE notification_type RENAME TO notification_type_old;
+CREATE TYPE notification_type AS ENUM ('mention');
+ALTER TABLE notifications ALTER COLUMN type TYPE notification_type USING type::text::notification_type;
+DROP TYPE notification_type_old;
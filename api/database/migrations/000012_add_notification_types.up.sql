@@ -0,0 +1,3 @@
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'assignment';
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'comment';
+ALTER TYPE notification_type ADD VALUE IF NOT EXISTS 'status_change';
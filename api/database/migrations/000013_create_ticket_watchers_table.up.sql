@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS ticket_watchers (
+    ticket_id INTEGER REFERENCES tickets (id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (ticket_id, user_id)
+);
+
+INSERT INTO ticket_watchers (ticket_id, user_id)
+SELECT id, created_by FROM tickets
+UNION
+SELECT ticket_id, user_id FROM assignments
+UNION
+SELECT ticket_id, user_id FROM comments
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS notification_preferences;
+DROP INDEX IF EXISTS notifications_email_pending_idx;
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS emailed_at,
+    DROP COLUMN IF EXISTS email_attempts,
+    DROP COLUMN IF EXISTS email_next_attempt_at,
+    DROP COLUMN IF EXISTS email_error;
@@ -0,0 +1,16 @@
+ALTER TABLE notifications
+    ADD COLUMN emailed_at TIMESTAMP,
+    ADD COLUMN email_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN email_next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN email_error TEXT;
+
+UPDATE notifications SET emailed_at = CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS notifications_email_pending_idx ON notifications (email_next_attempt_at) WHERE emailed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+    type notification_type NOT NULL,
+    email BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (user_id, type)
+);
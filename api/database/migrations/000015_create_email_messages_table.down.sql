@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS email_messages;
+ALTER TABLE tickets DROP COLUMN IF EXISTS email_token;
@@ -0,0 +1,8 @@
+ALTER TABLE tickets ADD COLUMN email_token VARCHAR(32) NOT NULL UNIQUE DEFAULT replace(gen_random_uuid()::text, '-', '');
+
+CREATE TABLE IF NOT EXISTS email_messages (
+    message_id TEXT PRIMARY KEY,
+    ticket_id INTEGER REFERENCES tickets (id) ON DELETE CASCADE NOT NULL,
+    comment_id INTEGER REFERENCES comments (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
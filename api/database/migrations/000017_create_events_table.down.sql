@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS events_notify ON events;
+DROP FUNCTION IF EXISTS notify_event;
+DROP TABLE IF EXISTS events;
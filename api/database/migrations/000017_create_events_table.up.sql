@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(255) NOT NULL,
+    ticket_id INTEGER NOT NULL,
+    internal BOOLEAN NOT NULL DEFAULT FALSE,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS events_created_at_idx ON events (created_at);
+
+CREATE OR REPLACE FUNCTION notify_event() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('events', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER events_notify AFTER INSERT ON events
+FOR EACH ROW EXECUTE FUNCTION notify_event();
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS ticket_reactions (
+    ticket_id INTEGER REFERENCES tickets (id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (ticket_id, user_id, emoji)
+);
+
+CREATE TABLE IF NOT EXISTS comment_reactions (
+    comment_id INTEGER REFERENCES comments (id) ON DELETE CASCADE,
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comment_id, user_id, emoji)
+);
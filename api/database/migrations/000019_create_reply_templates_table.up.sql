@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS reply_templates (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    content TEXT NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+ALTER TABLE labels
+DROP COLUMN IF EXISTS description,
+DROP COLUMN IF EXISTS color;
@@ -0,0 +1,3 @@
+ALTER TABLE labels
+ADD COLUMN color TEXT NOT NULL DEFAULT '#6b7280',
+ADD COLUMN description TEXT NOT NULL DEFAULT '';
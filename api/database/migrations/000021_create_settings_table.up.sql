@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    auto_create_labels BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO settings DEFAULT VALUES
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS label_groups;
+
+ALTER TABLE labels
+DROP COLUMN IF EXISTS scope;
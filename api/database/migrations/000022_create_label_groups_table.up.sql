@@ -0,0 +1,11 @@
+ALTER TABLE labels
+ADD COLUMN scope TEXT GENERATED ALWAYS AS (substring(name FROM '^(.*)::')) STORED;
+
+CREATE TABLE IF NOT EXISTS label_groups (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(50) NOT NULL UNIQUE,
+  exclusive BOOLEAN NOT NULL DEFAULT TRUE,
+  created_by INTEGER REFERENCES users (id) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
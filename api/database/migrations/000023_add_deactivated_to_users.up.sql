@@ -0,0 +1,2 @@
+ALTER TABLE users
+ADD COLUMN deactivated BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,9 @@
+ALTER TABLE assignments
+DROP COLUMN IF EXISTS rule_id;
+
+DROP TABLE IF EXISTS assignment_rules;
+
+DROP TYPE IF EXISTS assignment_strategy;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS out_of_office;
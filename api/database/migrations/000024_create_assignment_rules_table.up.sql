@@ -0,0 +1,19 @@
+ALTER TABLE users
+ADD COLUMN out_of_office BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TYPE assignment_strategy AS ENUM ('round_robin', 'least_open', 'fixed');
+
+CREATE TABLE IF NOT EXISTS assignment_rules (
+  id SERIAL PRIMARY KEY,
+  label_id INTEGER REFERENCES labels (id) ON DELETE CASCADE,
+  strategy assignment_strategy NOT NULL,
+  team_id INTEGER REFERENCES teams (id) ON DELETE CASCADE,
+  user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+  last_assigned_to INTEGER REFERENCES users (id) ON DELETE SET NULL,
+  created_by INTEGER REFERENCES users (id) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE assignments
+ADD COLUMN rule_id INTEGER REFERENCES assignment_rules (id) ON DELETE SET NULL;
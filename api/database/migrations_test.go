@@ -0,0 +1,19 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationVersions(t *testing.T) {
+	t.Parallel()
+
+	versions, err := migrationVersions()
+	require.NoError(t, err)
+	require.NotEmpty(t, versions)
+	require.Equal(t, uint(1), versions[0])
+	for i := 1; i < len(versions); i++ {
+		require.Equal(t, versions[i-1]+1, versions[i], "migration versions must be sequential")
+	}
+}
@@ -0,0 +1,37 @@
+-- name: GetAssignmentRules :many
+SELECT assignment_rules.*, labels.name AS label
+FROM assignment_rules
+LEFT JOIN labels ON assignment_rules.label_id = labels.id
+ORDER BY assignment_rules.label_id IS NULL, assignment_rules.id ASC;
+
+-- name: GetAssignmentRuleByID :one
+SELECT * FROM assignment_rules WHERE id = $1 LIMIT 1;
+
+-- name: LockAssignmentRuleByID :one
+SELECT * FROM assignment_rules WHERE id = $1 LIMIT 1 FOR UPDATE;
+
+-- name: CreateAssignmentRule :one
+INSERT INTO assignment_rules (label_id, strategy, team_id, user_id, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: DeleteAssignmentRuleByID :exec
+DELETE FROM assignment_rules
+WHERE id = $1;
+
+-- name: UpdateAssignmentRuleLastAssignedTo :exec
+UPDATE assignment_rules
+SET last_assigned_to = $2
+WHERE id = $1;
+
+-- name: GetAssignmentCandidates :many
+SELECT users.id, COUNT(tickets.id) AS open_tickets
+FROM team_members
+JOIN users ON team_members.user_id = users.id
+LEFT JOIN assignments ON users.id = assignments.user_id
+LEFT JOIN tickets ON assignments.ticket_id = tickets.id AND tickets.status = 'open'
+WHERE team_members.team_id = $1
+AND NOT users.deactivated
+AND NOT users.out_of_office
+GROUP BY users.id
+ORDER BY users.id ASC;
@@ -3,9 +3,18 @@ INSERT INTO assignments (ticket_id, user_id, assigned_by)
 VALUES ($1, $2, $3)
 RETURNING *;
 
--- name: DeleteAssignment :exec
+-- name: CreateRuleAssignment :one
+INSERT INTO assignments (ticket_id, user_id, assigned_by, rule_id)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetAssignmentByID :one
+SELECT * FROM assignments WHERE id = $1 LIMIT 1;
+
+-- name: DeleteAssignment :one
 DELETE FROM assignments
-WHERE id = $1;
+WHERE id = $1
+RETURNING *;
 
 -- name: DeleteAssignmentByTicketIDAndUserID :exec
 DELETE FROM assignments
@@ -0,0 +1,28 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (ticket_id, comment_id, filename, content_type, size, sha256, storage_key, uploaded_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetAttachmentByID :one
+SELECT * FROM attachments WHERE id = $1 LIMIT 1;
+
+-- name: GetAttachmentsByTicketID :many
+SELECT attachments.*, sqlc.embed(users)
+FROM attachments
+JOIN users ON attachments.uploaded_by = users.id
+LEFT JOIN comments ON attachments.comment_id = comments.id
+WHERE attachments.ticket_id = @ticket_id
+AND (@include_internal::boolean OR comments.visibility IS NULL OR comments.visibility = 'public')
+ORDER BY attachments.created_at ASC;
+
+-- name: GetAttachmentStorageKeysByTicketID :many
+SELECT storage_key FROM attachments WHERE ticket_id = $1;
+
+-- name: DeleteAttachment :exec
+DELETE FROM attachments
+WHERE id = $1;
+
+-- name: DeleteCommentAttachments :many
+DELETE FROM attachments
+WHERE comment_id = $1
+RETURNING storage_key;
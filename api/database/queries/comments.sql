@@ -1,6 +1,6 @@
 -- name: CreateComment :one
-INSERT INTO comments (ticket_id, user_id, content, reply_to)
-VALUES ($1, $2, $3, $4)
+INSERT INTO comments (ticket_id, user_id, content, reply_to, visibility)
+VALUES ($1, $2, $3, $4, COALESCE(sqlc.narg(visibility)::comment_visibility, 'public'))
 RETURNING *;
 
 -- name: DeleteComment :exec
@@ -12,12 +12,45 @@ SELECT * FROM comments WHERE id = $1 LIMIT 1;
 
 -- name: UpdateCommentByID :one
 UPDATE comments
-SET content = $2, updated_at = NOW()
+SET content = $2, updated_at = NOW(), edited_at = NOW()
 WHERE id = $1
 RETURNING *;
 
 -- name: GetCommentsByTicketID :many
-SELECT *, sqlc.embed(users)
+SELECT
+  *,
+  sqlc.embed(users),
+  (SELECT COUNT(*) FROM comment_revisions WHERE comment_revisions.comment_id = comments.id) AS revisions
 FROM comments
 JOIN users ON comments.user_id = users.id
-WHERE ticket_id = $1 ORDER BY comments.created_at ASC;
\ No newline at end of file
+WHERE ticket_id = @ticket_id
+AND (@include_internal::boolean OR comments.visibility = 'public')
+ORDER BY comments.created_at ASC;
+
+-- name: CountCommentReplies :one
+SELECT COUNT(*) FROM comments WHERE reply_to = $1;
+
+-- name: TombstoneComment :exec
+UPDATE comments
+SET content = '', deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1;
+
+
+-- name: CreateCommentRevision :one
+INSERT INTO comment_revisions (comment_id, content, edited_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetCommentRevisions :many
+SELECT comment_revisions.*, sqlc.embed(users)
+FROM comment_revisions
+JOIN users ON comment_revisions.edited_by = users.id
+WHERE comment_id = $1
+ORDER BY comment_revisions.created_at ASC;
+
+-- name: CountCommentRevisions :one
+SELECT COUNT(*) FROM comment_revisions WHERE comment_id = $1;
+
+-- name: DeleteCommentRevisions :exec
+DELETE FROM comment_revisions
+WHERE comment_id = $1;
@@ -0,0 +1,10 @@
+-- name: CreateEmailMessage :execrows
+INSERT INTO email_messages (message_id, ticket_id, comment_id)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetEmailMessage :one
+SELECT * FROM email_messages WHERE message_id = $1 LIMIT 1;
+
+-- name: GetTicketIDByEmailToken :one
+SELECT id FROM tickets WHERE email_token = $1 LIMIT 1;
@@ -0,0 +1,21 @@
+-- name: LockEvents :exec
+-- Held until the transaction ends so events are committed in id order and
+-- streams resuming after an id don't miss a later committed lower one.
+SELECT pg_advisory_xact_lock(hashtext('events'));
+
+-- name: CreateEvent :exec
+INSERT INTO events (type, ticket_id, internal, payload)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetEventByID :one
+SELECT * FROM events WHERE id = $1 LIMIT 1;
+
+-- name: GetEventsAfter :many
+SELECT * FROM events
+WHERE id > $1
+ORDER BY id
+LIMIT 500;
+
+-- name: DeleteExpiredEvents :exec
+DELETE FROM events
+WHERE created_at < NOW() - INTERVAL '1 day';
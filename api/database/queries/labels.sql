@@ -19,10 +19,52 @@ AND label_id = (
 );
 
 -- name: GetLabels :many
-SELECT * FROM labels;
+SELECT * FROM labels
+ORDER BY name ASC;
+
+-- name: GetLabelByID :one
+SELECT * FROM labels WHERE id = $1 LIMIT 1;
+
+-- name: GetLabelByName :one
+SELECT * FROM labels WHERE name = $1 LIMIT 1;
 
 -- name: CreateLabel :one
-INSERT INTO labels (name, created_by)
-VALUES ($1, $2)
+INSERT INTO labels (name, color, description, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdateLabelByID :one
+UPDATE labels
+SET name = $2, color = $3, description = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteLabelByID :exec
+DELETE FROM labels
+WHERE id = $1;
+
+
+-- name: GetLabelGroups :many
+SELECT * FROM label_groups
+ORDER BY name ASC;
+
+-- name: GetLabelGroupByID :one
+SELECT * FROM label_groups WHERE id = $1 LIMIT 1;
+
+-- name: GetLabelGroupByName :one
+SELECT * FROM label_groups WHERE name = $1 LIMIT 1;
+
+-- name: CreateLabelGroup :one
+INSERT INTO label_groups (name, exclusive, created_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UpdateLabelGroupByID :one
+UPDATE label_groups
+SET name = $2, exclusive = $3, updated_at = NOW()
+WHERE id = $1
 RETURNING *;
 
+-- name: DeleteLabelGroupByID :exec
+DELETE FROM label_groups
+WHERE id = $1;
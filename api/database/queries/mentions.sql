@@ -0,0 +1,20 @@
+-- name: CreateCommentMention :execrows
+INSERT INTO comment_mentions (comment_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteCommentMentionsExcept :exec
+DELETE FROM comment_mentions
+WHERE comment_id = @comment_id
+AND NOT (user_id = ANY(@user_ids::int[]));
+
+-- name: DeleteCommentMentions :exec
+DELETE FROM comment_mentions
+WHERE comment_id = $1;
+
+-- name: GetMentionsByCommentIDs :many
+SELECT comment_mentions.comment_id, sqlc.embed(users)
+FROM comment_mentions
+JOIN users ON comment_mentions.user_id = users.id
+WHERE comment_mentions.comment_id = ANY(@comment_ids::int[])
+ORDER BY comment_mentions.created_at ASC, users.username ASC;
@@ -0,0 +1,87 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (user_id, type, ticket_id, comment_id, actor_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetNotificationsByUserID :many
+SELECT notifications.*, tickets.title AS ticket_title, sqlc.embed(users)
+FROM notifications
+JOIN tickets ON notifications.ticket_id = tickets.id
+JOIN users ON notifications.actor_id = users.id
+WHERE notifications.user_id = @user_id
+AND (NOT @unread::boolean OR notifications.read_at IS NULL)
+ORDER BY notifications.created_at DESC, notifications.id DESC;
+
+-- name: MarkNotificationAsRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, NOW())
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: MarkAllNotificationsAsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: ClaimEmailNotifications :many
+-- Pending emails are leased for a few minutes so a crashed worker doesn't
+-- block them forever. Notifications older than a day and notifications of
+-- deactivated users are never emailed.
+WITH claimed AS (
+  UPDATE notifications
+  SET email_attempts = email_attempts + 1, email_next_attempt_at = NOW() + INTERVAL '5 minutes'
+  WHERE notifications.id IN (
+    SELECT n.id
+    FROM notifications AS n
+    JOIN users AS u ON u.id = n.user_id
+    LEFT JOIN notification_preferences AS p ON p.user_id = n.user_id AND p.type = n.type
+    WHERE n.emailed_at IS NULL
+    AND NOT u.deactivated
+    AND n.email_attempts < @max_attempts::int
+    AND n.email_next_attempt_at <= NOW()
+    AND n.created_at > NOW() - INTERVAL '1 day'
+    AND COALESCE(p.email, TRUE)
+    ORDER BY n.id
+    LIMIT @batch_size::int
+    FOR UPDATE OF n SKIP LOCKED
+  )
+  RETURNING *
+)
+SELECT
+  claimed.id,
+  claimed.type,
+  claimed.ticket_id,
+  claimed.email_attempts,
+  recipient.name AS recipient_name,
+  recipient.email AS recipient_email,
+  actor.name AS actor_name,
+  actor.username AS actor_username,
+  tickets.title AS ticket_title,
+  tickets.status AS ticket_status,
+  tickets.email_token AS ticket_email_token,
+  comments.content AS comment_content
+FROM claimed
+JOIN users AS recipient ON claimed.user_id = recipient.id
+JOIN users AS actor ON claimed.actor_id = actor.id
+JOIN tickets ON claimed.ticket_id = tickets.id
+LEFT JOIN comments ON claimed.comment_id = comments.id
+ORDER BY claimed.id;
+
+-- name: MarkNotificationEmailed :exec
+UPDATE notifications
+SET emailed_at = NOW(), email_error = NULL
+WHERE id = $1;
+
+-- name: MarkNotificationEmailFailed :exec
+UPDATE notifications
+SET email_error = @email_error::text, email_next_attempt_at = NOW() + @backoff_ms::int * INTERVAL '1 millisecond'
+WHERE id = @id;
+
+-- name: GetNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE user_id = $1;
+
+-- name: UpsertNotificationPreference :exec
+INSERT INTO notification_preferences (user_id, type, email)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, type) DO UPDATE SET email = EXCLUDED.email;
@@ -0,0 +1,43 @@
+-- name: AddTicketReaction :exec
+INSERT INTO ticket_reactions (ticket_id, user_id, emoji)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTicketReaction :exec
+DELETE FROM ticket_reactions
+WHERE ticket_id = $1 AND user_id = $2 AND emoji = $3;
+
+-- name: GetTicketReactions :many
+SELECT
+  ticket_id,
+  emoji,
+  COUNT(*) AS count,
+  bool_or(user_id = @user_id)::boolean AS reacted_by_me
+FROM ticket_reactions
+WHERE ticket_id = ANY(@ticket_ids::int[])
+GROUP BY ticket_id, emoji
+ORDER BY ticket_id, MIN(created_at) ASC;
+
+-- name: AddCommentReaction :exec
+INSERT INTO comment_reactions (comment_id, user_id, emoji)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveCommentReaction :exec
+DELETE FROM comment_reactions
+WHERE comment_id = $1 AND user_id = $2 AND emoji = $3;
+
+-- name: DeleteCommentReactions :exec
+DELETE FROM comment_reactions
+WHERE comment_id = $1;
+
+-- name: GetCommentReactions :many
+SELECT
+  comment_id,
+  emoji,
+  COUNT(*) AS count,
+  bool_or(user_id = @user_id)::boolean AS reacted_by_me
+FROM comment_reactions
+WHERE comment_id = ANY(@comment_ids::int[])
+GROUP BY comment_id, emoji
+ORDER BY comment_id, MIN(created_at) ASC;
@@ -0,0 +1,22 @@
+-- name: CreateReplyTemplate :one
+INSERT INTO reply_templates (name, content, shared, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetReplyTemplates :many
+SELECT * FROM reply_templates
+WHERE shared OR created_by = @user_id
+ORDER BY name ASC;
+
+-- name: GetReplyTemplateByID :one
+SELECT * FROM reply_templates WHERE id = $1 LIMIT 1;
+
+-- name: UpdateReplyTemplateByID :one
+UPDATE reply_templates
+SET name = $2, content = $3, shared = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteReplyTemplateByID :exec
+DELETE FROM reply_templates
+WHERE id = $1;
@@ -0,0 +1,75 @@
+-- name: GetWorkload :many
+SELECT
+  sqlc.embed(users),
+  COALESCE((
+    SELECT substring(l.name FROM length(l.scope) + 3)
+    FROM ticket_labels AS tl
+    JOIN labels AS l ON tl.label_id = l.id
+    WHERE tl.ticket_id = tickets.id AND l.scope = 'priority'
+    ORDER BY l.name ASC
+    LIMIT 1
+  ), '')::text AS priority,
+  CASE
+    WHEN tickets.created_at > NOW() - INTERVAL '1 day' THEN '0-1d'
+    WHEN tickets.created_at > NOW() - INTERVAL '7 days' THEN '1-7d'
+    WHEN tickets.created_at > NOW() - INTERVAL '30 days' THEN '7-30d'
+    ELSE '30d+'
+  END::text AS age_bucket,
+  COUNT(tickets.id) AS open_tickets
+FROM assignments
+JOIN tickets ON assignments.ticket_id = tickets.id
+JOIN users ON assignments.user_id = users.id
+WHERE
+  tickets.status = 'open'
+  AND CASE
+    WHEN @title::text != '' THEN
+      tickets.title ILIKE concat('%', @title, '%')
+    ELSE true
+  END
+  AND CASE
+    WHEN cardinality(@labels::text[]) > 0 OR cardinality(@label_scopes::text[]) > 0 THEN
+      EXISTS (
+        SELECT 1
+        FROM ticket_labels AS tl
+        JOIN labels AS l ON tl.label_id = l.id
+        WHERE tl.ticket_id = tickets.id AND (l.name = ANY(@labels) OR l.scope = ANY(@label_scopes))
+      )
+    ELSE true
+  END
+  AND CASE
+    WHEN @assignee::text != '' THEN
+      EXISTS (
+        SELECT 1
+        FROM assignments AS a
+        JOIN users AS u ON a.user_id = u.id
+        WHERE a.ticket_id = tickets.id AND u.username = @assignee
+      )
+    ELSE true
+  END
+  AND CASE
+    WHEN @assignee_team::text != '' THEN
+      EXISTS (
+        SELECT 1
+        FROM team_assignments AS ta
+        JOIN teams AS t ON ta.team_id = t.id
+        WHERE ta.ticket_id = tickets.id AND t.name = @assignee_team
+      )
+    ELSE true
+  END
+  AND CASE
+    WHEN @member_id::int != 0 THEN
+      EXISTS (
+        SELECT 1
+        FROM assignments AS a
+        WHERE a.ticket_id = tickets.id AND a.user_id = @member_id
+      )
+      OR EXISTS (
+        SELECT 1
+        FROM team_assignments AS ta
+        JOIN team_members AS tm ON ta.team_id = tm.team_id
+        WHERE ta.ticket_id = tickets.id AND tm.user_id = @member_id
+      )
+    ELSE true
+  END
+GROUP BY users.id, priority, age_bucket
+ORDER BY users.id ASC, priority ASC, age_bucket ASC;
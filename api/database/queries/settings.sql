@@ -0,0 +1,7 @@
+-- name: GetSettings :one
+SELECT * FROM settings LIMIT 1;
+
+-- name: UpdateSettings :one
+UPDATE settings
+SET auto_create_labels = $1, updated_at = NOW()
+RETURNING *;
@@ -0,0 +1,73 @@
+-- name: CreateTeam :one
+INSERT INTO teams (name, created_by)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetTeams :many
+SELECT
+  teams.*,
+  array_remove(array_agg(team_members.user_id), NULL)::integer[] AS members
+FROM teams
+LEFT JOIN team_members ON teams.id = team_members.team_id
+GROUP BY teams.id
+ORDER BY teams.name ASC;
+
+-- name: GetTeamByID :one
+SELECT
+  teams.*,
+  array_remove(array_agg(team_members.user_id), NULL)::integer[] AS members
+FROM teams
+LEFT JOIN team_members ON teams.id = team_members.team_id
+WHERE teams.id = @id
+GROUP BY teams.id
+LIMIT 1;
+
+-- name: GetTeamByName :one
+SELECT * FROM teams WHERE name = $1 LIMIT 1;
+
+-- name: UpdateTeamByID :one
+UPDATE teams
+SET name = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTeamByID :exec
+DELETE FROM teams
+WHERE id = $1;
+
+-- name: AddTeamMember :exec
+INSERT INTO team_members (team_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTeamMember :exec
+DELETE FROM team_members
+WHERE team_id = $1 AND user_id = $2;
+
+-- name: GetTeamMembers :many
+SELECT sqlc.embed(users)
+FROM team_members
+JOIN users ON team_members.user_id = users.id
+WHERE team_members.team_id = $1
+ORDER BY team_members.created_at ASC;
+
+-- name: CreateTeamAssignment :one
+INSERT INTO team_assignments (ticket_id, team_id, assigned_by)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTeamAssignmentByID :one
+SELECT * FROM team_assignments WHERE id = $1 LIMIT 1;
+
+-- name: DeleteTeamAssignment :one
+DELETE FROM team_assignments
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTeamAssignmentByTicketIDAndTeamID :exec
+DELETE FROM team_assignments
+WHERE ticket_id = $1 AND team_id = $2;
+
+-- name: GetTeamAssignmentsByTicketID :many
+SELECT * FROM team_assignments
+WHERE ticket_id = $1;
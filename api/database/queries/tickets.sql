@@ -8,12 +8,16 @@ SELECT
   tickets.*,
   sqlc.embed(users),
   array_remove(array_agg(DISTINCT labels.name), NULL)::text[] AS labels,
-  array_remove(array_agg(DISTINCT assignments.user_id), NULL)::integer[] AS assigned_to
+  array_remove(array_agg(DISTINCT assignments.user_id), NULL)::integer[] AS assigned_to,
+  array_remove(array_agg(DISTINCT team_assignments.team_id), NULL)::integer[] AS assigned_teams,
+  array_remove(array_agg(DISTINCT ticket_watchers.user_id), NULL)::integer[] AS watchers
 FROM tickets
 LEFT JOIN ticket_labels ON tickets.id = ticket_labels.ticket_id
 LEFT JOIN labels ON ticket_labels.label_id = labels.id
 LEFT JOIN users ON tickets.created_by = users.id
 LEFT JOIN assignments ON tickets.id = assignments.ticket_id
+LEFT JOIN team_assignments ON tickets.id = team_assignments.ticket_id
+LEFT JOIN ticket_watchers ON tickets.id = ticket_watchers.ticket_id
 WHERE tickets.id = @id
 GROUP BY tickets.id, users.id
 LIMIT 1;
@@ -39,12 +43,16 @@ SELECT
   tickets.*,
   sqlc.embed(users),
   array_remove(array_agg(DISTINCT labels.name), NULL)::text[] AS labels,
-  array_remove(array_agg(DISTINCT assignments.user_id), NULL)::integer[] AS assigned_to
+  array_remove(array_agg(DISTINCT assignments.user_id), NULL)::integer[] AS assigned_to,
+  array_remove(array_agg(DISTINCT team_assignments.team_id), NULL)::integer[] AS assigned_teams,
+  array_remove(array_agg(DISTINCT ticket_watchers.user_id), NULL)::integer[] AS watchers
 FROM tickets
 LEFT JOIN ticket_labels ON tickets.id = ticket_labels.ticket_id
 LEFT JOIN labels ON ticket_labels.label_id = labels.id
 LEFT JOIN users ON tickets.created_by = users.id
 LEFT JOIN assignments ON tickets.id = assignments.ticket_id
+LEFT JOIN team_assignments ON tickets.id = team_assignments.ticket_id
+LEFT JOIN ticket_watchers ON tickets.id = ticket_watchers.ticket_id
 WHERE
   CASE 
     WHEN @title::text != '' THEN
@@ -62,8 +70,49 @@ WHERE
     ELSE true
   END
   AND CASE
-    WHEN cardinality(@labels::text[]) > 0 THEN
-      labels.name = ANY(@labels)
+    WHEN cardinality(@labels::text[]) > 0 OR cardinality(@label_scopes::text[]) > 0 THEN
+      labels.name = ANY(@labels) OR labels.scope = ANY(@label_scopes)
     ELSE true
   END
-GROUP BY tickets.id, users.id;
\ No newline at end of file
+  AND CASE
+    WHEN @assignee::text != '' THEN
+      EXISTS (
+        SELECT 1
+        FROM assignments AS a
+        JOIN users AS u ON a.user_id = u.id
+        WHERE a.ticket_id = tickets.id AND u.username = @assignee
+      )
+    ELSE true
+  END
+  AND CASE
+    WHEN @assignee_team::text != '' THEN
+      EXISTS (
+        SELECT 1
+        FROM team_assignments AS ta
+        JOIN teams AS t ON ta.team_id = t.id
+        WHERE ta.ticket_id = tickets.id AND t.name = @assignee_team
+      )
+    ELSE true
+  END
+  AND CASE
+    WHEN @member_id::int != 0 THEN
+      EXISTS (
+        SELECT 1
+        FROM assignments AS a
+        WHERE a.ticket_id = tickets.id AND a.user_id = @member_id
+      )
+      OR EXISTS (
+        SELECT 1
+        FROM team_assignments AS ta
+        JOIN team_members AS tm ON ta.team_id = tm.team_id
+        WHERE ta.ticket_id = tickets.id AND tm.user_id = @member_id
+      )
+    ELSE true
+  END
+GROUP BY tickets.id, users.id
+ORDER BY
+  CASE
+    WHEN @sort::text = 'reactions' THEN
+      (SELECT COUNT(*) FROM ticket_reactions WHERE ticket_reactions.ticket_id = tickets.id)
+  END DESC,
+  tickets.id ASC;
\ No newline at end of file
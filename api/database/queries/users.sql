@@ -23,7 +23,7 @@ DELETE FROM users WHERE id = $1;
 
 -- name: UpdateUserByID :one
 UPDATE users
-SET name = $2, username = $3, email = $4, profile_picture_url = $5, role = $6, updated_at = NOW()
+SET name = $2, username = $3, email = $4, profile_picture_url = $5, role = $6, deactivated = $7, out_of_office = $8, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
 
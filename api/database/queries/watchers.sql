@@ -0,0 +1,15 @@
+-- name: AddTicketWatcher :exec
+INSERT INTO ticket_watchers (ticket_id, user_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTicketWatcher :exec
+DELETE FROM ticket_watchers
+WHERE ticket_id = $1 AND user_id = $2;
+
+-- name: GetTicketWatchers :many
+SELECT sqlc.embed(users)
+FROM ticket_watchers
+JOIN users ON ticket_watchers.user_id = users.id
+WHERE ticket_watchers.ticket_id = $1
+ORDER BY ticket_watchers.created_at ASC;
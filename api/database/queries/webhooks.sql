@@ -0,0 +1,86 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (url, events, secret, active, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWebhooks :many
+SELECT * FROM webhooks ORDER BY id;
+
+-- name: GetWebhookByID :one
+SELECT * FROM webhooks WHERE id = $1 LIMIT 1;
+
+-- name: UpdateWebhookByID :one
+UPDATE webhooks
+SET url = $2, events = $3, secret = $4, active = $5, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWebhookByID :exec
+DELETE FROM webhooks WHERE id = $1;
+
+-- name: EnqueueWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+SELECT webhooks.id, @event::text, @payload::jsonb
+FROM webhooks
+WHERE webhooks.active AND @event::text = ANY(webhooks.events);
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, event, payload)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY id DESC
+LIMIT 100;
+
+-- name: GetWebhookDeliveryByID :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 AND webhook_id = $2
+LIMIT 1;
+
+-- name: ClaimWebhookDeliveries :many
+-- Pending deliveries are leased for a few minutes so a crashed worker doesn't
+-- block them forever.
+WITH claimed AS (
+  UPDATE webhook_deliveries
+  SET attempts = attempts + 1, next_attempt_at = NOW() + INTERVAL '5 minutes'
+  WHERE webhook_deliveries.id IN (
+    SELECT d.id
+    FROM webhook_deliveries AS d
+    WHERE d.status = 'pending'
+    AND d.next_attempt_at <= NOW()
+    ORDER BY d.id
+    LIMIT @batch_size::int
+    FOR UPDATE OF d SKIP LOCKED
+  )
+  RETURNING *
+)
+SELECT
+  claimed.id,
+  claimed.event,
+  claimed.payload,
+  claimed.attempts,
+  webhooks.url AS webhook_url,
+  webhooks.secret AS webhook_secret
+FROM claimed
+JOIN webhooks ON claimed.webhook_id = webhooks.id
+ORDER BY claimed.id;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET status = 'succeeded', response_status = $2, response_body = $3, error = NULL, delivered_at = NOW()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+-- The delivery is given up once it runs out of attempts, otherwise it is
+-- retried after the backoff.
+UPDATE webhook_deliveries
+SET
+  status = CASE WHEN attempts >= @max_attempts::int THEN 'failed' ELSE 'pending' END::webhook_delivery_status,
+  response_status = sqlc.narg(response_status),
+  response_body = sqlc.narg(response_body),
+  error = @error::text,
+  next_attempt_at = NOW() + @backoff_ms::int * INTERVAL '1 millisecond'
+WHERE id = @id;
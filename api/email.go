@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/BrunoQuaresma/openticket/api/mailer"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	emailPollInterval = 500 * time.Millisecond
+	emailBatchSize    = 20
+	emailSendTimeout  = 30 * time.Second
+
+	// maxRetryBackoff caps the wait between retries of emails and webhooks.
+	maxRetryBackoff = time.Hour
+)
+
+// retryBackoff is base doubled on every failed attempt after the first, up to
+// maxRetryBackoff.
+func retryBackoff(base time.Duration, attempts int32) time.Duration {
+	backoff := base
+	for i := int32(1); i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+var NotificationTypes = []string{
+	string(sqlc.NotificationTypeMention),
+	string(sqlc.NotificationTypeAssignment),
+	string(sqlc.NotificationTypeComment),
+	string(sqlc.NotificationTypeStatusChange),
+}
+
+// SetMailer enables email notifications. Pending notifications are sent by a
+// background worker started with the server.
+func (server *Server) SetMailer(m *mailer.Mailer) {
+	server.mailer = m
+}
+
+type EmailTemplateData struct {
+	RecipientName  string
+	ActorName      string
+	ActorUsername  string
+	TicketID       int32
+	TicketTitle    string
+	TicketStatus   string
+	TicketURL      string
+	CommentContent string
+}
+
+func (server *Server) startEmailWorker() {
+	if server.mailer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	server.stopEmailWorker = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(emailPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := server.sendPendingEmails(ctx)
+				if err != nil && ctx.Err() == nil {
+					log.Println("error sending notification emails: " + err.Error())
+				}
+			}
+		}
+	}()
+}
+
+func (server *Server) sendPendingEmails(ctx context.Context) error {
+	config := server.mailer.Config()
+	notifications, err := server.db.Queries().ClaimEmailNotifications(ctx, sqlc.ClaimEmailNotificationsParams{
+		MaxAttempts: int32(config.MaxAttempts),
+		BatchSize:   emailBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, notification := range notifications {
+		data := EmailTemplateData{
+			RecipientName:  notification.RecipientName,
+			ActorName:      notification.ActorName,
+			ActorUsername:  notification.ActorUsername,
+			TicketID:       notification.TicketID,
+			TicketTitle:    notification.TicketTitle,
+			TicketStatus:   string(notification.TicketStatus),
+			CommentContent: notification.CommentContent.String,
+		}
+		if config.BaseURL != "" {
+			data.TicketURL = fmt.Sprintf("%s/tickets/%d", strings.TrimSuffix(config.BaseURL, "/"), notification.TicketID)
+		}
+
+		err := server.sendEmail(ctx, string(notification.Type), notification.RecipientEmail, notification.TicketEmailToken, data)
+		if err != nil {
+			backoff := retryBackoff(config.RetryBackoff, notification.EmailAttempts)
+			err = server.db.Queries().MarkNotificationEmailFailed(ctx, sqlc.MarkNotificationEmailFailedParams{
+				ID:         notification.ID,
+				EmailError: err.Error(),
+				BackoffMs:  int32(backoff.Milliseconds()),
+			})
+		} else {
+			err = server.db.Queries().MarkNotificationEmailed(ctx, notification.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (server *Server) sendEmail(ctx context.Context, event string, to string, threadToken string, data EmailTemplateData) error {
+	msg, err := mailer.Render(event, to, data)
+	if err != nil {
+		return err
+	}
+	msg.ThreadToken = threadToken
+
+	ctx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	defer cancel()
+	return server.mailer.Send(ctx, msg)
+}
+
+type NotificationPreference struct {
+	Type  string `json:"type" validate:"required,oneof=mention assignment comment status_change"`
+	Email bool   `json:"email"`
+}
+
+type NotificationPreferencesResponse = Response[[]NotificationPreference]
+
+func (server *Server) notificationPreferences(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	preferences, err := server.getNotificationPreferences(c, server.db.Queries(), user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationPreferencesResponse{Data: preferences})
+}
+
+type PatchNotificationPreferencesRequest struct {
+	Preferences []NotificationPreference `json:"preferences" validate:"required,dive"`
+}
+
+func (server *Server) patchNotificationPreferences(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	var req PatchNotificationPreferencesRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var preferences []NotificationPreference
+	err := server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		for _, preference := range req.Preferences {
+			err := qtx.UpsertNotificationPreference(ctx, sqlc.UpsertNotificationPreferenceParams{
+				UserID: user.ID,
+				Type:   sqlc.NotificationType(preference.Type),
+				Email:  preference.Email,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		preferences, err = server.getNotificationPreferences(ctx, qtx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, NotificationPreferencesResponse{Data: preferences})
+}
+
+// getNotificationPreferences returns the preference of every notification
+// type. Emails are enabled for the types the user never changed.
+func (server *Server) getNotificationPreferences(ctx context.Context, qtx *sqlc.Queries, userID int32) ([]NotificationPreference, error) {
+	rows, err := qtx.GetNotificationPreferences(ctx, userID)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	emailByType := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		emailByType[string(row.Type)] = row.Email
+	}
+
+	preferences := make([]NotificationPreference, len(NotificationTypes))
+	for i, notificationType := range NotificationTypes {
+		email, ok := emailByType[notificationType]
+		preferences[i] = NotificationPreference{
+			Type:  notificationType,
+			Email: !ok || email,
+		}
+	}
+	return preferences, nil
+}
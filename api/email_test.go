@@ -0,0 +1,100 @@
+package api_test
+
+import (
+	"mime"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/mailer"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailNotifications(t *testing.T) {
+	t.Parallel()
+
+	fakeSMTP := testutil.NewFakeSMTP(t)
+	tEnv := testutil.NewEnv(t)
+	tEnv.Server().SetMailer(mailer.New(fakeSMTP.Config()))
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, memberRes := testutil.NewMember(t, &sdk)
+	memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+	var preferencesRes api.NotificationPreferencesResponse
+	httpRes, err := memberSdk.PatchNotificationPreferences(api.PatchNotificationPreferencesRequest{
+		Preferences: []api.NotificationPreference{{Type: "comment", Email: false}},
+	}, &preferencesRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.Contains(t, preferencesRes.Data, api.NotificationPreference{Type: "comment", Email: false})
+	require.Contains(t, preferencesRes.Data, api.NotificationPreference{Type: "assignment", Email: true})
+
+	// The first delivery attempt fails and is retried by the worker.
+	fakeSMTP.FailNext(1)
+
+	var ticketRes api.CreateTicketResponse
+	_, err = sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+		AssignedTo:  []int32{memberRes.Data.ID},
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	require.Eventually(t, func() bool {
+		return len(fakeSMTP.Messages()) == 1
+	}, 10*time.Second, 100*time.Millisecond)
+
+	msg := fakeSMTP.Messages()[0]
+	require.Equal(t, []string{member.Email}, msg.To)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Parse(t).Header.Get("Subject"))
+	require.NoError(t, err, "error decoding subject")
+	require.Contains(t, subject, "You were assigned to")
+	require.Contains(t, subject, ticketRes.Data.Title)
+
+	var commentRes api.CreateCommentResponse
+	_, err = sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: "Please check the logs from last night.",
+	}, &commentRes)
+	require.NoError(t, err, "error creating comment")
+
+	var statusRes api.PatchTicketStatusResponse
+	_, err = sdk.PatchTicketStatus(ticketRes.Data.ID, api.PatchTicketStatusRequest{Status: "closed"}, &statusRes)
+	require.NoError(t, err, "error updating ticket status")
+
+	// The comment email is skipped because of the member's preferences.
+	require.Eventually(t, func() bool {
+		return len(fakeSMTP.Messages()) == 2
+	}, 10*time.Second, 100*time.Millisecond)
+	subject, err = new(mime.WordDecoder).DecodeHeader(fakeSMTP.Messages()[1].Parse(t).Header.Get("Subject"))
+	require.NoError(t, err, "error decoding subject")
+	require.Contains(t, subject, "is now closed")
+}
+
+func TestPatchNotificationPreferences_InvalidType(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	var patchRes api.NotificationPreferencesResponse
+	httpRes, err := sdk.PatchNotificationPreferences(api.PatchNotificationPreferencesRequest{
+		Preferences: []api.NotificationPreference{{Type: "comment", Email: false}, {Type: "digest", Email: false}},
+	}, &patchRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+	testutil.RequireValidationError(t, patchRes.Errors, "type", "oneof")
+
+	// Nothing is saved when the request is invalid.
+	var preferencesRes api.NotificationPreferencesResponse
+	_, err = sdk.NotificationPreferences(&preferencesRes)
+	require.NoError(t, err, "error getting preferences")
+	require.Contains(t, preferencesRes.Data, api.NotificationPreference{Type: "comment", Email: true})
+}
@@ -0,0 +1,323 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	EventTicketCreated         = "ticket.created"
+	EventTicketUpdated         = "ticket.updated"
+	EventTicketStatusChanged   = "ticket.status_changed"
+	EventTicketDeleted         = "ticket.deleted"
+	EventCommentCreated        = "comment.created"
+	EventCommentUpdated        = "comment.updated"
+	EventCommentDeleted        = "comment.deleted"
+	EventAssignmentCreated     = "assignment.created"
+	EventAssignmentDeleted     = "assignment.deleted"
+	EventTeamAssignmentCreated = "team_assignment.created"
+	EventTeamAssignmentDeleted = "team_assignment.deleted"
+	EventLabelCreated          = "label.created"
+	EventLabelUpdated          = "label.updated"
+	EventLabelDeleted          = "label.deleted"
+)
+
+type EventPayload struct {
+	Event     string `json:"event"`
+	CreatedAt string `json:"created_at"`
+	Actor     User   `json:"actor"`
+	Data      any    `json:"data"`
+}
+
+type CommentEventData struct {
+	TicketID int32 `json:"ticket_id"`
+	Comment
+}
+
+func commentEventData(comment sqlc.Comment, author sqlc.User) CommentEventData {
+	return CommentEventData{
+		TicketID: comment.TicketID,
+		Comment: Comment{
+			ID:         comment.ID,
+			Content:    comment.Content,
+			CreatedAt:  comment.CreatedAt.Time.UTC().String(),
+			ReplyTo:    comment.ReplyTo.Int32,
+			Visibility: string(comment.Visibility),
+			CreatedBy:  userResponse(author),
+		},
+	}
+}
+
+// publishEvent queues the event for every active webhook subscribed to it and
+// records ticket events for the event stream. It runs in the same transaction
+// as the change so events are never lost or sent for changes that were rolled
+// back.
+func publishEvent(ctx context.Context, qtx *sqlc.Queries, event string, actor *sqlc.User, data any) error {
+	payload, err := json.Marshal(EventPayload{
+		Event:     event,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Actor:     userResponse(*actor),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = qtx.EnqueueWebhookDeliveries(ctx, sqlc.EnqueueWebhookDeliveriesParams{
+		Event:   event,
+		Payload: payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	params := sqlc.CreateEventParams{Type: event, Payload: payload}
+	switch data := data.(type) {
+	case Ticket:
+		params.TicketID = data.ID
+	case CommentEventData:
+		params.TicketID = data.TicketID
+		params.Internal = data.Visibility == string(sqlc.CommentVisibilityInternal)
+	case Assignment:
+		params.TicketID = data.TicketID
+	case TeamAssignment:
+		params.TicketID = data.TicketID
+	default:
+		// Only ticket related events are streamed.
+		return nil
+	}
+
+	// Ids are assigned on insert, so concurrent transactions could commit
+	// them out of order. The lock keeps them in commit order until the
+	// transaction ends.
+	err = qtx.LockEvents(ctx)
+	if err != nil {
+		return err
+	}
+	return qtx.CreateEvent(ctx, params)
+}
+
+const (
+	eventsChannel          = "events"
+	eventsPageSize         = 500
+	eventStreamBuffer      = 64
+	eventStreamHeartbeat   = 15 * time.Second
+	eventStreamRetryMillis = 3000
+	eventListenRetry       = time.Second
+	eventCleanupInterval   = time.Hour
+)
+
+// eventHub fans out the events received from Postgres to the open streams.
+// Every server instance listens to the same channel so clients get the same
+// events whichever instance they are connected to.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan sqlc.Event]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[chan sqlc.Event]struct{})}
+}
+
+func (h *eventHub) subscribe() chan sqlc.Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan sqlc.Event, eventStreamBuffer)
+	h.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (h *eventHub) unsubscribe(ch chan sqlc.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+// broadcast sends the event to every subscriber. Subscribers that can't keep
+// up are disconnected, they resume from their last event when reconnecting.
+func (h *eventHub) broadcast(event sqlc.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// disconnectAll closes every stream. It is used when notifications may have
+// been missed so clients resume from their last event.
+func (h *eventHub) disconnectAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (server *Server) startEventHub() {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	server.stopEventHub = func() {
+		cancel()
+		wg.Wait()
+		server.events.disconnectAll()
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			err := server.db.Listen(ctx, eventsChannel, func(payload string) {
+				id, err := strconv.ParseInt(payload, 10, 64)
+				if err != nil {
+					return
+				}
+				event, err := server.db.Queries().GetEventByID(ctx, id)
+				if err != nil {
+					log.Println("error getting event: " + err.Error())
+					return
+				}
+				server.events.broadcast(event)
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			log.Println("error listening for events: " + err.Error())
+			server.events.disconnectAll()
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(eventListenRetry):
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(eventCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := server.db.Queries().DeleteExpiredEvents(ctx)
+				if err != nil && ctx.Err() == nil {
+					log.Println("error deleting expired events: " + err.Error())
+				}
+			}
+		}
+	}()
+}
+
+// canReceiveEvent reports whether the user is allowed to see the event.
+func canReceiveEvent(user *sqlc.User, event sqlc.Event) bool {
+	return !event.Internal || canViewInternalComments(user)
+}
+
+// eventStream streams ticket, comment and assignment events with Server-Sent
+// Events. Clients resume with the Last-Event-ID header, or the last_event_id
+// query parameter for clients that can't set headers. Events are kept for a
+// day.
+func (server *Server) eventStream(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var after int64
+	if lastEventID != "" {
+		var err error
+		after, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || after < 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+				Errors: []ValidationError{{Field: "Last-Event-ID", Validator: "number"}},
+			})
+			return
+		}
+	}
+
+	// Subscribe before reading the missed events so nothing published in
+	// between is lost. Events received twice are skipped.
+	events := server.events.subscribe()
+	defer server.events.unsubscribe(events)
+
+	var missed []sqlc.Event
+	for lastEventID != "" {
+		page, err := server.db.Queries().GetEventsAfter(c, after)
+		if err != nil && err != pgx.ErrNoRows {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get events"})
+			return
+		}
+		missed = append(missed, page...)
+		if len(page) < eventsPageSize {
+			break
+		}
+		after = page[len(page)-1].ID
+	}
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	io.WriteString(c.Writer, "retry: "+strconv.Itoa(eventStreamRetryMillis)+"\n\n")
+
+	sent := make(map[int64]bool, len(missed))
+	for _, event := range missed {
+		sent[event.ID] = true
+		writeEvent(c, user, event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if !sent[event.ID] {
+				writeEvent(c, user, event)
+			}
+			return true
+		case <-heartbeat.C:
+			io.WriteString(w, ": heartbeat\n\n")
+			return true
+		}
+	})
+}
+
+func writeEvent(c *gin.Context, user *sqlc.User, event sqlc.Event) {
+	if !canReceiveEvent(user, event) {
+		return
+	}
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(event.ID, 10),
+		Event: event.Type,
+		Data:  string(event.Payload),
+	})
+}
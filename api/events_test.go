@@ -0,0 +1,163 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/BrunoQuaresma/openticket/sdk"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+type streamEvent struct {
+	id      int64
+	event   string
+	payload api.EventPayload
+}
+
+// openEventStream connects to the event stream and parses its events in the
+// background.
+func openEventStream(t *testing.T, client sdk.Client, lastEventID int64) <-chan streamEvent {
+	httpRes, err := client.EventStream(lastEventID)
+	require.NoError(t, err, "error opening event stream")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.Equal(t, "text/event-stream", httpRes.Header.Get("Content-Type"))
+	t.Cleanup(func() { httpRes.Body.Close() })
+
+	events := make(chan streamEvent, 100)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(httpRes.Body)
+		var current streamEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				if current.event != "" {
+					events <- current
+				}
+				current = streamEvent{}
+				continue
+			}
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				current.id, _ = strconv.ParseInt(value, 10, 64)
+			case "event":
+				current.event = value
+			case "data":
+				json.Unmarshal([]byte(value), &current.payload)
+			}
+		}
+	}()
+	return events
+}
+
+// waitForEvent returns the first event matching fn, failing after a timeout.
+func waitForEvent(t *testing.T, events <-chan streamEvent, fn func(streamEvent) bool) streamEvent {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			require.True(t, ok, "event stream closed")
+			if fn(event) {
+				return event
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for event")
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	adminSdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	requester := api.CreateUserRequest{
+		Name:     gofakeit.Name(),
+		Username: gofakeit.Username(),
+		Email:    gofakeit.Email(),
+		Password: testutil.FakePassword(),
+		Role:     "requester",
+	}
+	var requesterRes api.CreateUserResponse
+	_, err := adminSdk.CreateUser(requester, &requesterRes)
+	require.NoError(t, err, "error creating requester")
+	requesterSdk := tEnv.AuthSDK(requester.Email, requester.Password)
+
+	adminEvents := openEventStream(t, adminSdk, 0)
+	requesterEvents := openEventStream(t, requesterSdk, 0)
+
+	var ticketRes api.CreateTicketResponse
+	_, err = adminSdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	isTicket := func(event streamEvent) bool {
+		data, ok := event.payload.Data.(map[string]any)
+		return ok && (data["id"] == float64(ticketRes.Data.ID) || data["ticket_id"] == float64(ticketRes.Data.ID))
+	}
+
+	created := waitForEvent(t, adminEvents, func(event streamEvent) bool {
+		return event.event == api.EventTicketCreated && isTicket(event)
+	})
+	require.Equal(t, setup.Res().Data.ID, created.payload.Actor.ID)
+	waitForEvent(t, requesterEvents, func(event streamEvent) bool {
+		return event.event == api.EventTicketCreated && isTicket(event)
+	})
+
+	var internalRes api.CreateCommentResponse
+	_, err = adminSdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content:    "Triage note for the team only.",
+		Visibility: "internal",
+	}, &internalRes)
+	require.NoError(t, err, "error creating internal comment")
+
+	var publicRes api.CreateCommentResponse
+	_, err = adminSdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: "We are looking into it.",
+	}, &publicRes)
+	require.NoError(t, err, "error creating public comment")
+
+	internal := waitForEvent(t, adminEvents, func(event streamEvent) bool {
+		return event.event == api.EventCommentCreated && isTicket(event)
+	})
+	require.Equal(t, float64(internalRes.Data.ID), internal.payload.Data.(map[string]any)["id"])
+
+	// Requesters never receive internal comments, the next comment they see
+	// is the public one.
+	public := waitForEvent(t, requesterEvents, func(event streamEvent) bool {
+		return event.event == api.EventCommentCreated && isTicket(event)
+	})
+	require.Equal(t, float64(publicRes.Data.ID), public.payload.Data.(map[string]any)["id"])
+
+	t.Run("success: resume from the last event", func(t *testing.T) {
+		resumed := openEventStream(t, adminSdk, created.id)
+		event := waitForEvent(t, resumed, func(event streamEvent) bool {
+			return event.event == api.EventCommentCreated && isTicket(event)
+		})
+		require.Equal(t, internal.id, event.id)
+	})
+
+	t.Run("error: invalid last event id", func(t *testing.T) {
+		httpRes, err := adminSdk.EventStream(-1)
+		require.NoError(t, err, "error making request")
+		defer httpRes.Body.Close()
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		io.Copy(io.Discard, httpRes.Body)
+	})
+}
@@ -0,0 +1,259 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageSize is the largest raw message accepted by Parse.
+const MaxMessageSize = 25 << 20
+
+const maxPartDepth = 10
+
+var ErrMessageTooLarge = errors.New("message too large")
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// Email is an inbound RFC 5322 message reduced to what is needed to open or
+// reply to a ticket.
+type Email struct {
+	From        *mail.Address
+	Recipients  []*mail.Address
+	Subject     string
+	MessageID   string
+	InReplyTo   []string
+	References  []string
+	Text        string
+	Attachments []Attachment
+
+	html string
+}
+
+func Parse(r io.Reader) (*Email, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, MaxMessageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > MaxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := msg.Header.AddressList("From")
+	if err != nil || len(from) == 0 {
+		return nil, fmt.Errorf("invalid from header: %w", err)
+	}
+
+	email := Email{
+		From:       from[0],
+		Subject:    decodeHeader(msg.Header.Get("Subject")),
+		InReplyTo:  messageIDs(msg.Header.Get("In-Reply-To")),
+		References: messageIDs(msg.Header.Get("References")),
+	}
+	if ids := messageIDs(msg.Header.Get("Message-ID")); len(ids) > 0 {
+		email.MessageID = ids[0]
+	}
+	for _, header := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		addresses, err := msg.Header.AddressList(header)
+		if err == nil {
+			email.Recipients = append(email.Recipients, addresses...)
+		}
+	}
+
+	err = email.walk(
+		msg.Header.Get("Content-Type"),
+		msg.Header.Get("Content-Disposition"),
+		msg.Header.Get("Content-Transfer-Encoding"),
+		msg.Body,
+		0,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if email.Text == "" && email.html != "" {
+		email.Text = htmlToText(email.html)
+	}
+
+	return &email, nil
+}
+
+// PlusTags returns the tags of plus-addressed recipients, for example "abc"
+// for support+abc@example.com.
+func (e *Email) PlusTags() []string {
+	var tags []string
+	for _, recipient := range e.Recipients {
+		local, _, ok := strings.Cut(recipient.Address, "@")
+		if !ok {
+			continue
+		}
+		_, tag, ok := strings.Cut(local, "+")
+		if ok && tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ThreadIDs returns the message IDs the email replies to, the most recent
+// first.
+func (e *Email) ThreadIDs() []string {
+	ids := append([]string{}, e.InReplyTo...)
+	for i := len(e.References) - 1; i >= 0; i-- {
+		ids = append(ids, e.References[i])
+	}
+	return ids
+}
+
+func (e *Email) walk(contentType string, disposition string, encoding string, body io.Reader, depth int) error {
+	if depth > maxPartDepth {
+		return errors.New("too many nested parts")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	dispositionType, dispositionParams, _ := mime.ParseMediaType(disposition)
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			err = e.walk(
+				part.Header.Get("Content-Type"),
+				part.Header.Get("Content-Disposition"),
+				part.Header.Get("Content-Transfer-Encoding"),
+				part,
+				depth+1,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransfer(encoding, body))
+	if err != nil {
+		return err
+	}
+
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	filename = decodeHeader(filename)
+
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if dispositionType == "attachment" || (filename != "" && !isText) || mediaType == "message/rfc822" {
+		if filename == "" {
+			filename = "attachment"
+			if mediaType == "message/rfc822" {
+				filename = "message.eml"
+			}
+		}
+		e.Attachments = append(e.Attachments, Attachment{
+			Filename:    filename,
+			ContentType: mediaType,
+			Content:     content,
+		})
+		return nil
+	}
+
+	switch mediaType {
+	case "text/plain":
+		if e.Text == "" {
+			e.Text = decodeCharset(params["charset"], content)
+		}
+	case "text/html":
+		if e.html == "" {
+			e.html = decodeCharset(params["charset"], content)
+		}
+	}
+	return nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// decodeCharset converts text to UTF-8. Only UTF-8 and Latin-1 are converted,
+// other charsets are kept as long as they are valid UTF-8.
+func decodeCharset(charset string, content []byte) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "windows-1252":
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+	if !utf8.Valid(content) {
+		return strings.ToValidUTF8(string(content), "�")
+	}
+	return string(content)
+}
+
+func decodeHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+var messageIDRegexp = regexp.MustCompile(`<([^<>\s]+)>`)
+
+func messageIDs(value string) []string {
+	var ids []string
+	for _, match := range messageIDRegexp.FindAllStringSubmatch(value, -1) {
+		ids = append(ids, match[1])
+	}
+	return ids
+}
+
+var (
+	htmlBlockRegexp  = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/li|/tr|/h[1-6])\s*/?>`)
+	htmlIgnoreRegexp = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	htmlQuoteRegexp  = regexp.MustCompile(`(?is)<blockquote[^>]*>.*</blockquote>`)
+	htmlTagRegexp    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+func htmlToText(s string) string {
+	s = htmlIgnoreRegexp.ReplaceAllString(s, "")
+	s = htmlQuoteRegexp.ReplaceAllString(s, "")
+	s = htmlBlockRegexp.ReplaceAllString(s, "\n")
+	s = htmlTagRegexp.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
@@ -0,0 +1,130 @@
+package inbound_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api/inbound"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	raw := strings.Join([]string{
+		"From: =?utf-8?q?Ad=C3=A1_Lovelace?= <ada@example.com>",
+		"To: Support <support+abc123@openticket.test>",
+		"Subject: Re: Printer is on fire",
+		"Message-ID: <reply-1@example.com>",
+		"In-Reply-To: <abc123.1@openticket.test>",
+		"References: <first@example.com> <abc123.1@openticket.test>",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="outer"`,
+		"",
+		"--outer",
+		`Content-Type: multipart/alternative; boundary="inner"`,
+		"",
+		"--inner",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"It's still burning.=20",
+		"",
+		"--inner",
+		"Content-Type: text/html; charset=utf-8",
+		"",
+		"<p>It's still burning.</p>",
+		"--inner--",
+		"",
+		"--outer",
+		`Content-Type: text/plain; name="error.log"`,
+		`Content-Disposition: attachment; filename="error.log"`,
+		"Content-Transfer-Encoding: base64",
+		"",
+		"UEMgTE9BRCBMRVRURVI=",
+		"--outer--",
+		"",
+	}, "\r\n")
+
+	email, err := inbound.Parse(strings.NewReader(raw))
+	require.NoError(t, err, "error parsing message")
+	require.Equal(t, "ada@example.com", email.From.Address)
+	require.Equal(t, "Adá Lovelace", email.From.Name)
+	require.Equal(t, "Re: Printer is on fire", email.Subject)
+	require.Equal(t, "reply-1@example.com", email.MessageID)
+	require.Equal(t, []string{"abc123"}, email.PlusTags())
+	require.Equal(t, []string{"abc123.1@openticket.test", "abc123.1@openticket.test", "first@example.com"}, email.ThreadIDs())
+	require.Equal(t, "It's still burning. \r\n", email.Text)
+	require.Len(t, email.Attachments, 1)
+	require.Equal(t, "error.log", email.Attachments[0].Filename)
+	require.Equal(t, "PC LOAD LETTER", string(email.Attachments[0].Content))
+}
+
+func TestParse_HTMLOnly(t *testing.T) {
+	t.Parallel()
+
+	raw := "From: ada@example.com\r\nSubject: Hi\r\nContent-Type: text/html\r\n\r\n<div>Hello&nbsp;there<br>second line</div><blockquote>old</blockquote>"
+	email, err := inbound.Parse(strings.NewReader(raw))
+	require.NoError(t, err, "error parsing message")
+	require.Equal(t, "Hello there\nsecond line", email.Text)
+}
+
+func TestStripReply(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "gmail",
+			text: "Thanks, that fixed it!\n\nOn Mon, Jan 1, 2024 at 10:00 AM Support <support@openticket.test> wrote:\n> Please restart the printer.\n",
+			want: "Thanks, that fixed it!",
+		},
+		{
+			name: "wrapped reply header",
+			text: "Still broken.\n\nOn Mon, Jan 1, 2024 at 10:00 AM Support <support@openticket.test>\nwrote:\n> Please restart the printer.",
+			want: "Still broken.",
+		},
+		{
+			name: "outlook",
+			text: "See attached.\r\n\r\n________________________________\r\nFrom: Support <support@openticket.test>\r\nSent: Monday, January 1, 2024 10:00 AM\r\nSubject: Printer",
+			want: "See attached.",
+		},
+		{
+			name: "original message",
+			text: "Done.\n-----Original Message-----\nFrom: Support",
+			want: "Done.",
+		},
+		{
+			name: "signature",
+			text: "The office printer is on fire.\n\n-- \nAda Lovelace\nAnalytical Engines Ltd.",
+			want: "The office printer is on fire.",
+		},
+		{
+			name: "mobile signature",
+			text: "On my way.\n\nSent from my iPhone",
+			want: "On my way.",
+		},
+		{
+			name: "inline quotes",
+			text: "> Can you restart it?\nYes, I did.\n> And then?\nNothing happened.",
+			want: "Yes, I did.\nNothing happened.",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, c.want, inbound.StripReply(c.text))
+		})
+	}
+}
+
+func TestCleanSubject(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Printer is on fire", inbound.CleanSubject("Re: Fwd: RE[2]: Printer is on fire"))
+	require.Equal(t, "Reimbursement", inbound.CleanSubject("Reimbursement"))
+}
@@ -0,0 +1,61 @@
+package inbound
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	wroteRegexp           = regexp.MustCompile(`(?i)^(on|am|el|le)\s.+\s(wrote|schrieb|escribió|a écrit)\s?:$`)
+	onRegexp              = regexp.MustCompile(`(?i)^on\s.+`)
+	originalRegexp        = regexp.MustCompile(`(?i)^-{2,}\s*(original message|forwarded message)\s*-{2,}`)
+	outlookHeaderRegexp   = regexp.MustCompile(`^(From|De|Von):\s.+`)
+	outlookNextRegexp     = regexp.MustCompile(`^(Sent|Date|Envoyé|Gesendet|To):\s`)
+	separatorRegexp       = regexp.MustCompile(`^_{5,}$`)
+	mobileSignatureRegexp = regexp.MustCompile(`(?i)^sent from my\s`)
+)
+
+// StripReply removes quoted text and signatures from the plain-text body of
+// an email so only what the sender wrote is kept.
+func StripReply(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var kept []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		next := ""
+		if i+1 < len(lines) {
+			next = strings.TrimSpace(lines[i+1])
+		}
+
+		// Signature delimiter from RFC 3676 and common mobile signatures.
+		if line == "-- " || line == "--" || mobileSignatureRegexp.MatchString(trimmed) {
+			break
+		}
+		// Reply headers such as "On Mon, 1 Jan 2024, Ada <ada@example.com> wrote:",
+		// which some clients wrap over two lines.
+		if wroteRegexp.MatchString(trimmed) || (onRegexp.MatchString(trimmed) && strings.HasSuffix(next, "wrote:")) {
+			break
+		}
+		if originalRegexp.MatchString(trimmed) || separatorRegexp.MatchString(trimmed) {
+			break
+		}
+		if outlookHeaderRegexp.MatchString(trimmed) && outlookNextRegexp.MatchString(next) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		kept = append(kept, strings.TrimRight(line, " \t"))
+	}
+
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+var subjectPrefixRegexp = regexp.MustCompile(`(?i)^\s*((re|fw|fwd|aw|wg|sv|tr)\s*(\[\d+\])?\s*:\s*)+`)
+
+// CleanSubject removes reply and forward prefixes from a subject.
+func CleanSubject(subject string) string {
+	return strings.TrimSpace(subjectPrefixRegexp.ReplaceAllString(subject, ""))
+}
@@ -0,0 +1,321 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/BrunoQuaresma/openticket/api/inbound"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type InboundEmailResult struct {
+	TicketID  int32 `json:"ticket_id"`
+	CommentID int32 `json:"comment_id"`
+	// Created is true when the email opened a new ticket instead of replying
+	// to an existing one.
+	Created bool `json:"created"`
+}
+
+type InboundEmailResponse = Response[InboundEmailResult]
+
+// inboundEmail ingests a raw RFC 5322 message forwarded by the mail relay.
+// Replies to a ticket become comments, any other message opens a new ticket.
+// Messages are deduplicated by their Message-ID so the relay can safely retry.
+func (server *Server) inboundEmail(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can ingest emails"})
+		return
+	}
+
+	email, err := inbound.Parse(c.Request.Body)
+	if err != nil {
+		if errors.Is(err, inbound.ErrMessageTooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, Response[any]{Message: err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "invalid email: " + err.Error(),
+			Errors:  []ValidationError{{Field: "message", Validator: "rfc5322"}},
+		})
+		return
+	}
+
+	content := inbound.StripReply(email.Text)
+	if content == "" && len(email.Attachments) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "email has no content",
+			Errors:  []ValidationError{{Field: "body", Validator: "required"}},
+		})
+		return
+	}
+
+	var (
+		result      InboundEmailResult
+		duplicate   bool
+		storageKeys []string
+	)
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		if email.MessageID != "" {
+			existing, err := qtx.GetEmailMessage(ctx, email.MessageID)
+			if err == nil {
+				duplicate = true
+				result = InboundEmailResult{TicketID: existing.TicketID, CommentID: existing.CommentID.Int32}
+				return nil
+			}
+			if err != pgx.ErrNoRows {
+				return err
+			}
+		}
+
+		ticketID, err := emailTicketID(ctx, qtx, email)
+		if err != nil {
+			return err
+		}
+
+		var requesterID int32
+		if ticketID != 0 {
+			ticket, err := qtx.GetTicketByID(ctx, ticketID)
+			if err != nil {
+				return err
+			}
+			requesterID = ticket.CreatedBy
+		}
+
+		sender, err := emailSender(ctx, qtx, email.From, requesterID)
+		if err != nil {
+			return err
+		}
+
+		var comment sqlc.Comment
+		if ticketID != 0 {
+			comment, _, err = insertComment(ctx, qtx, &sender, ticketID, CreateCommentRequest{Content: content})
+			if err != nil {
+				return err
+			}
+		} else {
+			title := inbound.CleanSubject(email.Subject)
+			if utf8.RuneCountInString(title) < 3 {
+				title = "(no subject)"
+			}
+			if runes := []rune(title); len(runes) > 70 {
+				title = string(runes[:70])
+			}
+
+			// Emails with only attachments still need a description.
+			description := content
+			if description == "" {
+				description = "(no message, see the attached files)"
+			}
+			req := CreateTicketRequest{Title: title, Description: description}
+			err = server.validate.Struct(req)
+			if err != nil {
+				return err
+			}
+
+			var ticket sqlc.GetTicketByIDRow
+			ticket, comment, err = insertTicket(ctx, qtx, &sender, req)
+			if err != nil {
+				return err
+			}
+			ticketID = ticket.ID
+			result.Created = true
+		}
+		result.TicketID = ticketID
+		result.CommentID = comment.ID
+
+		for _, attachment := range email.Attachments {
+			saved, err := server.saveAttachment(ctx, qtx, ticketID, pgtype.Int4{Int32: comment.ID, Valid: true}, sender.ID, attachment.Filename, attachment.Content)
+			switch err.(type) {
+			case nil:
+				storageKeys = append(storageKeys, saved.StorageKey)
+			case AttachmentTooLargeError, UnsupportedAttachmentTypeError:
+				log.Printf("skipping attachment %q of email %s: %s", attachment.Filename, email.MessageID, err)
+			default:
+				return err
+			}
+		}
+
+		if email.MessageID == "" {
+			return nil
+		}
+		// When a concurrent retry stored the message first everything created
+		// here is rolled back.
+		rows, err := qtx.CreateEmailMessage(ctx, sqlc.CreateEmailMessageParams{
+			MessageID: email.MessageID,
+			TicketID:  ticketID,
+			CommentID: pgtype.Int4{Int32: comment.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return DuplicateEmailError{}
+		}
+		return nil
+	})
+
+	if err != nil {
+		for _, key := range storageKeys {
+			server.storage.Delete(context.Background(), key)
+		}
+
+		// Concurrent retries of the same message conflict on the Message-ID
+		// or on the rows they both create, like the sender. Once one of them
+		// is stored the others are duplicates.
+		if email.MessageID != "" {
+			existing, getErr := server.db.Queries().GetEmailMessage(c, email.MessageID)
+			if getErr == nil {
+				c.JSON(http.StatusOK, InboundEmailResponse{
+					Data: InboundEmailResult{TicketID: existing.TicketID, CommentID: existing.CommentID.Int32},
+				})
+				return
+			}
+		}
+
+		switch err := err.(type) {
+		case validator.ValidationErrors:
+			c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{Message: "invalid email", Errors: validationErrors(err)})
+		case PermissionDeniedError:
+			c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to ingest email"})
+		}
+		return
+	}
+
+	if duplicate {
+		c.JSON(http.StatusOK, InboundEmailResponse{Data: result})
+		return
+	}
+	c.JSON(http.StatusCreated, InboundEmailResponse{Data: result})
+}
+
+type DuplicateEmailError struct{}
+
+func (e DuplicateEmailError) Error() string {
+	return "email was already ingested"
+}
+
+// emailSender returns the user matching the sender address. Unknown senders
+// are registered as requesters so they can follow their tickets.
+//
+// The From header can't be trusted, so emails from staff addresses are only
+// accepted from requesterID, the requester of the ticket being replied to.
+// Anything else is rejected instead of being posted as the staff member.
+func emailSender(ctx context.Context, qtx *sqlc.Queries, from *mail.Address, requesterID int32) (sqlc.User, error) {
+	address := from.Address
+	user, err := qtx.GetUserByEmail(ctx, address)
+	if err == nil {
+		if user.Role != sqlc.RoleRequester && user.ID != requesterID {
+			return sqlc.User{}, PermissionDeniedError{Message: "emails from staff addresses are only accepted on their own tickets"}
+		}
+		return user, nil
+	}
+	if err != pgx.ErrNoRows {
+		return user, err
+	}
+
+	local, _, _ := strings.Cut(strings.ToLower(address), "@")
+	name := strings.TrimSpace(from.Name)
+	if name == "" {
+		name = local
+	}
+
+	base := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, local)
+	if len(base) > 10 {
+		base = base[:10]
+	}
+
+	var username string
+	for {
+		token, err := secureToken()
+		if err != nil {
+			return sqlc.User{}, err
+		}
+		username = base + token[:4]
+		_, err = qtx.GetUserByUsername(ctx, username)
+		if err == pgx.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return sqlc.User{}, err
+		}
+	}
+
+	// Requesters created by email have no usable password until an admin sets
+	// one.
+	password, err := secureToken()
+	if err != nil {
+		return sqlc.User{}, err
+	}
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return sqlc.User{}, err
+	}
+
+	return qtx.CreateUser(ctx, sqlc.CreateUserParams{
+		Name:         name,
+		Username:     username,
+		Email:        address,
+		PasswordHash: string(h),
+		Role:         sqlc.RoleRequester,
+	})
+}
+
+// emailTicketID finds the ticket an email replies to. It returns 0 when the
+// email is not a reply. Plus-addressed recipients take precedence over the
+// threading headers because they survive clients that drop them.
+func emailTicketID(ctx context.Context, qtx *sqlc.Queries, email *inbound.Email) (int32, error) {
+	for _, tag := range email.PlusTags() {
+		ticketID, err := qtx.GetTicketIDByEmailToken(ctx, tag)
+		if err == nil {
+			return ticketID, nil
+		}
+		if err != pgx.ErrNoRows {
+			return 0, err
+		}
+	}
+
+	for _, id := range email.ThreadIDs() {
+		message, err := qtx.GetEmailMessage(ctx, id)
+		if err == nil {
+			return message.TicketID, nil
+		}
+		if err != pgx.ErrNoRows {
+			return 0, err
+		}
+
+		// Notification emails use the ticket token as the first part of their
+		// Message-ID.
+		token, _, ok := strings.Cut(id, ".")
+		if !ok {
+			continue
+		}
+		ticketID, err := qtx.GetTicketIDByEmailToken(ctx, token)
+		if err == nil {
+			return ticketID, nil
+		}
+		if err != pgx.ErrNoRows {
+			return 0, err
+		}
+	}
+
+	return 0, nil
+}
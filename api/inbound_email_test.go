@@ -0,0 +1,326 @@
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/mailer"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func rawEmail(headers map[string]string, body string) string {
+	var b strings.Builder
+	for key, value := range headers {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return b.String()
+}
+
+func TestInboundEmail(t *testing.T) {
+	t.Parallel()
+
+	fakeSMTP := testutil.NewFakeSMTP(t)
+	tEnv := testutil.NewEnv(t)
+	tEnv.Server().SetMailer(mailer.New(fakeSMTP.Config()))
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	newTicketEmail := func(t *testing.T, from string) api.InboundEmailResult {
+		var res api.InboundEmailResponse
+		httpRes, err := sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":       from,
+			"To":         "support@openticket.test",
+			"Subject":    "Printer is on fire",
+			"Message-ID": "<" + gofakeit.UUID() + "@mail.example.com>",
+		}, "The printer on the second floor is on fire.\n")), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.True(t, res.Data.Created)
+		return res.Data
+	}
+
+	t.Run("success: new ticket from an unknown sender", func(t *testing.T) {
+		t.Parallel()
+
+		result := newTicketEmail(t, "Ada Lovelace <"+gofakeit.Email()+">")
+
+		var ticketRes api.TicketResponse
+		_, err := sdk.Ticket(result.TicketID, &ticketRes)
+		require.NoError(t, err, "error getting ticket")
+		require.Equal(t, "Printer is on fire", ticketRes.Data.Title)
+		require.Equal(t, "Ada Lovelace", ticketRes.Data.CreatedBy.Name)
+		require.Equal(t, "requester", ticketRes.Data.CreatedBy.Role)
+	})
+
+	t.Run("success: reply by In-Reply-To strips quoted text", func(t *testing.T) {
+		t.Parallel()
+
+		from := gofakeit.Email()
+		messageID := "<" + gofakeit.UUID() + "@mail.example.com>"
+		var res api.InboundEmailResponse
+		_, err := sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":       from,
+			"To":         "support@openticket.test",
+			"Subject":    "VPN is down",
+			"Message-ID": messageID,
+		}, "I can't connect to the VPN since this morning.\n")), &res)
+		require.NoError(t, err, "error making request")
+		ticketID := res.Data.TicketID
+
+		httpRes, err := sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":        from,
+			"To":          "support@openticket.test",
+			"Subject":     "Re: VPN is down",
+			"Message-ID":  "<" + gofakeit.UUID() + "@mail.example.com>",
+			"In-Reply-To": messageID,
+		}, "It works again after a restart.\n\nOn Mon, 1 Jan 2024, Support wrote:\n> I can't connect to the VPN since this morning.\n")), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.False(t, res.Data.Created)
+		require.Equal(t, ticketID, res.Data.TicketID)
+
+		var commentsRes api.CommentsResponse
+		_, err = sdk.Comments(ticketID, &commentsRes, nil)
+		require.NoError(t, err, "error getting comments")
+		require.Len(t, commentsRes.Data, 2)
+		require.Equal(t, "It works again after a restart.", commentsRes.Data[1].Content)
+	})
+
+	t.Run("success: reply by plus address", func(t *testing.T) {
+		t.Parallel()
+
+		from := gofakeit.Email()
+		result := newTicketEmail(t, from)
+
+		var commentRes api.CreateCommentResponse
+		_, err := sdk.CreateComment(result.TicketID, api.CreateCommentRequest{
+			Content: "Please leave the building.",
+		}, &commentRes)
+		require.NoError(t, err, "error creating comment")
+
+		var notification *mail.Message
+		require.Eventually(t, func() bool {
+			for _, msg := range fakeSMTP.Messages() {
+				if slices.Contains(msg.To, from) {
+					notification = msg.Parse(t)
+					return true
+				}
+			}
+			return false
+		}, 10*time.Second, 100*time.Millisecond)
+		replyTo := notification.Header.Get("Reply-To")
+		require.Contains(t, replyTo, "support+")
+
+		// Some clients drop the threading headers, the plus address is enough.
+		var res api.InboundEmailResponse
+		httpRes, err := sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":       from,
+			"To":         replyTo,
+			"Subject":    "Re: Printer is on fire",
+			"Message-ID": "<" + gofakeit.UUID() + "@mail.example.com>",
+		}, "Done, everyone is outside.\n\n-- \nSent by a very long signature\n")), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.False(t, res.Data.Created)
+		require.Equal(t, result.TicketID, res.Data.TicketID)
+
+		var commentsRes api.CommentsResponse
+		_, err = sdk.Comments(result.TicketID, &commentsRes, nil)
+		require.NoError(t, err, "error getting comments")
+		require.Len(t, commentsRes.Data, 3)
+		require.Equal(t, "Done, everyone is outside.", commentsRes.Data[2].Content)
+	})
+
+	t.Run("success: attachments are saved", func(t *testing.T) {
+		t.Parallel()
+
+		body := "--boundary\n" +
+			"Content-Type: text/plain; charset=utf-8\n\n" +
+			"Logs attached.\n" +
+			"--boundary\n" +
+			"Content-Type: text/plain\n" +
+			"Content-Disposition: attachment; filename=\"server.log\"\n\n" +
+			"2024-05-01 12:00:00 ERROR connection refused\n" +
+			"--boundary--\n"
+		var res api.InboundEmailResponse
+		httpRes, err := sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":         gofakeit.Email(),
+			"To":           "support@openticket.test",
+			"Subject":      "Server errors",
+			"Message-ID":   "<" + gofakeit.UUID() + "@mail.example.com>",
+			"MIME-Version": "1.0",
+			"Content-Type": `multipart/mixed; boundary="boundary"`,
+		}, body)), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		var attachmentsRes api.AttachmentsResponse
+		_, err = sdk.Attachments(res.Data.TicketID, &attachmentsRes)
+		require.NoError(t, err, "error getting attachments")
+		require.Len(t, attachmentsRes.Data, 1)
+		require.Equal(t, "server.log", attachmentsRes.Data[0].Filename)
+		require.Equal(t, res.Data.CommentID, attachmentsRes.Data[0].CommentID)
+	})
+
+	t.Run("success: attachments without a message", func(t *testing.T) {
+		t.Parallel()
+
+		body := "--boundary\n" +
+			"Content-Type: text/plain\n" +
+			"Content-Disposition: attachment; filename=\"server.log\"\n\n" +
+			"2024-05-01 12:00:00 ERROR connection refused\n" +
+			"--boundary--\n"
+		var res api.InboundEmailResponse
+		httpRes, err := sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":         gofakeit.Email(),
+			"To":           "support@openticket.test",
+			"Subject":      "Server errors",
+			"Message-ID":   "<" + gofakeit.UUID() + "@mail.example.com>",
+			"MIME-Version": "1.0",
+			"Content-Type": `multipart/mixed; boundary="boundary"`,
+		}, body)), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		var commentsRes api.CommentsResponse
+		_, err = sdk.Comments(res.Data.TicketID, &commentsRes, nil)
+		require.NoError(t, err, "error getting comments")
+		require.NotEmpty(t, commentsRes.Data[0].Content)
+	})
+
+	t.Run("error: description too short", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.InboundEmailResponse
+		httpRes, err := sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":       gofakeit.Email(),
+			"To":         "support@openticket.test",
+			"Subject":    "Help",
+			"Message-ID": "<" + gofakeit.UUID() + "@mail.example.com>",
+		}, "Help me\n")), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "description", "min")
+	})
+
+	t.Run("success: duplicate messages are ignored", func(t *testing.T) {
+		t.Parallel()
+
+		raw := rawEmail(map[string]string{
+			"From":       gofakeit.Email(),
+			"To":         "support@openticket.test",
+			"Subject":    "Duplicate",
+			"Message-ID": "<" + gofakeit.UUID() + "@mail.example.com>",
+		}, "This message is delivered twice.\n")
+
+		var first api.InboundEmailResponse
+		httpRes, err := sdk.InboundEmail(strings.NewReader(raw), &first)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		var second api.InboundEmailResponse
+		httpRes, err = sdk.InboundEmail(strings.NewReader(raw), &second)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Equal(t, first.Data.TicketID, second.Data.TicketID)
+		require.Equal(t, first.Data.CommentID, second.Data.CommentID)
+	})
+
+	t.Run("success: concurrent duplicates create one ticket", func(t *testing.T) {
+		t.Parallel()
+
+		raw := rawEmail(map[string]string{
+			"From":       gofakeit.Email(),
+			"To":         "support@openticket.test",
+			"Subject":    "Retried",
+			"Message-ID": "<" + gofakeit.UUID() + "@mail.example.com>",
+		}, "This message is retried by the relay.\n")
+
+		var wg sync.WaitGroup
+		results := make([]api.InboundEmailResponse, 5)
+		statuses := make([]int, len(results))
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				httpRes, err := sdk.InboundEmail(strings.NewReader(raw), &results[i])
+				if err == nil {
+					statuses[i] = httpRes.StatusCode
+				}
+			}()
+		}
+		wg.Wait()
+
+		created := 0
+		for i, status := range statuses {
+			require.Contains(t, []int{http.StatusCreated, http.StatusOK}, status)
+			require.Equal(t, results[0].Data.TicketID, results[i].Data.TicketID)
+			if status == http.StatusCreated {
+				created++
+			}
+		}
+		require.Equal(t, 1, created)
+	})
+
+	t.Run("error: staff addresses are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		member, _ := testutil.NewMember(t, &sdk)
+
+		var res api.InboundEmailResponse
+		httpRes, err := sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":       member.Email,
+			"To":         "support@openticket.test",
+			"Subject":    "Printer is on fire",
+			"Message-ID": "<" + gofakeit.UUID() + "@mail.example.com>",
+		}, "The printer on the second floor is on fire.\n")), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+
+		messageID := "<" + gofakeit.UUID() + "@mail.example.com>"
+		_, err = sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":       gofakeit.Email(),
+			"To":         "support@openticket.test",
+			"Subject":    "Printer is on fire",
+			"Message-ID": messageID,
+		}, "The printer on the second floor is on fire.\n")), &res)
+		require.NoError(t, err, "error creating ticket")
+
+		httpRes, err = sdk.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":        member.Email,
+			"To":          "support@openticket.test",
+			"Subject":     "Re: Printer is on fire",
+			"Message-ID":  "<" + gofakeit.UUID() + "@mail.example.com>",
+			"In-Reply-To": messageID,
+		}, "The fire department is on the way.\n")), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+
+	t.Run("error: member", func(t *testing.T) {
+		t.Parallel()
+
+		member, _ := testutil.NewMember(t, &sdk)
+		memberSDK := tEnv.AuthSDK(member.Email, member.Password)
+
+		var res api.InboundEmailResponse
+		httpRes, err := memberSDK.InboundEmail(strings.NewReader(rawEmail(map[string]string{
+			"From":    gofakeit.Email(),
+			"Subject": "Hello",
+		}, "Hello there, this is a test.\n")), &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// labelScope returns the scope of a scoped label, the part before the last
+// "::". For example, the scope of type::bug is type.
+func labelScope(name string) string {
+	i := strings.LastIndex(name, "::")
+	if i == -1 {
+		return ""
+	}
+	return name[:i]
+}
+
+// exclusiveLabels keeps at most one label of each exclusive group. A label
+// that is not in current replaces the other labels of its group, otherwise
+// the last one wins.
+func exclusiveLabels(ctx context.Context, qtx *sqlc.Queries, names []string, current []string) ([]string, error) {
+	groups, err := qtx.GetLabelGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusive := make(map[string]bool)
+	for _, group := range groups {
+		exclusive[group.Name] = group.Exclusive
+	}
+
+	kept := make(map[string]string)
+	for _, name := range names {
+		scope := labelScope(name)
+		if !exclusive[scope] {
+			continue
+		}
+		if prev, ok := kept[scope]; ok && !slices.Contains(current, prev) && slices.Contains(current, name) {
+			continue
+		}
+		kept[scope] = name
+	}
+
+	var labels []string
+	for _, name := range names {
+		scope := labelScope(name)
+		if exclusive[scope] && kept[scope] != name {
+			continue
+		}
+		labels = append(labels, name)
+	}
+	return labels, nil
+}
+
+type LabelGroup struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Exclusive bool   `json:"exclusive"`
+}
+
+func labelGroupResponse(group sqlc.LabelGroup) LabelGroup {
+	return LabelGroup{
+		ID:        int(group.ID),
+		Name:      group.Name,
+		Exclusive: group.Exclusive,
+	}
+}
+
+type LabelGroupNotFoundError struct{}
+
+func (e LabelGroupNotFoundError) Error() string {
+	return "label group not found"
+}
+
+type LabelGroupNameAlreadyInUseError struct{}
+
+func (e LabelGroupNameAlreadyInUseError) Error() string {
+	return "label group name already in use"
+}
+
+type LabelGroupsResponse = Response[[]LabelGroup]
+
+func (s *Server) labelGroups(c *gin.Context) {
+	groups, err := s.db.Queries().GetLabelGroups(c)
+	if err != nil && err != pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get label groups"})
+		return
+	}
+
+	data := make([]LabelGroup, len(groups))
+	for i, group := range groups {
+		data[i] = labelGroupResponse(group)
+	}
+	c.JSON(http.StatusOK, LabelGroupsResponse{Data: data})
+}
+
+type CreateLabelGroupRequest struct {
+	Name      string `json:"name" validate:"required,max=50"`
+	Exclusive *bool  `json:"exclusive,omitempty"`
+}
+
+type CreateLabelGroupResponse = Response[LabelGroup]
+
+// A group holds the labels of its scope, the group type holds type::bug and
+// type::feature.
+func (s *Server) createLabelGroup(c *gin.Context) {
+	user := s.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can create label groups"})
+		return
+	}
+
+	var req CreateLabelGroupRequest
+	s.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	exclusive := true
+	if req.Exclusive != nil {
+		exclusive = *req.Exclusive
+	}
+
+	var group sqlc.LabelGroup
+	err := s.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetLabelGroupByName(ctx, req.Name)
+		if err == nil {
+			return LabelGroupNameAlreadyInUseError{}
+		}
+		if err != pgx.ErrNoRows {
+			return err
+		}
+
+		group, err = qtx.CreateLabelGroup(ctx, sqlc.CreateLabelGroupParams{
+			Name:      req.Name,
+			Exclusive: exclusive,
+			CreatedBy: user.ID,
+		})
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateLabelGroupResponse{Data: labelGroupResponse(group)})
+	case LabelGroupNameAlreadyInUseError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "name", Validator: "unique"},
+			},
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create label group"})
+	}
+}
+
+type PatchLabelGroupRequest struct {
+	Name      string `json:"name,omitempty" validate:"omitempty,max=50"`
+	Exclusive *bool  `json:"exclusive,omitempty"`
+}
+
+type PatchLabelGroupResponse = Response[LabelGroup]
+
+// Making a group exclusive doesn't touch tickets that already hold several of
+// its labels, it's enforced the next time their labels change.
+func (s *Server) patchLabelGroup(c *gin.Context) {
+	user := s.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can update label groups"})
+		return
+	}
+
+	groupId, err := strconv.ParseInt(c.Param("groupId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "label group not found"})
+		return
+	}
+
+	var req PatchLabelGroupRequest
+	s.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var updatedGroup sqlc.LabelGroup
+	err = s.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		group, err := qtx.GetLabelGroupByID(ctx, int32(groupId))
+		if err != nil {
+			return LabelGroupNotFoundError{}
+		}
+
+		params := sqlc.UpdateLabelGroupByIDParams{
+			ID:        group.ID,
+			Name:      group.Name,
+			Exclusive: group.Exclusive,
+		}
+		if req.Name != "" && req.Name != group.Name {
+			_, err = qtx.GetLabelGroupByName(ctx, req.Name)
+			if err == nil {
+				return LabelGroupNameAlreadyInUseError{}
+			}
+			if err != pgx.ErrNoRows {
+				return err
+			}
+			params.Name = req.Name
+		}
+		if req.Exclusive != nil {
+			params.Exclusive = *req.Exclusive
+		}
+
+		updatedGroup, err = qtx.UpdateLabelGroupByID(ctx, params)
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusOK, PatchLabelGroupResponse{Data: labelGroupResponse(updatedGroup)})
+	case LabelGroupNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	case LabelGroupNameAlreadyInUseError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "name", Validator: "unique"},
+			},
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update label group"})
+	}
+}
+
+// deleteLabelGroup keeps the labels of the group, they just stop being
+// exclusive.
+func (s *Server) deleteLabelGroup(c *gin.Context) {
+	user := s.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can delete label groups"})
+		return
+	}
+
+	groupId, err := strconv.ParseInt(c.Param("groupId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "label group not found"})
+		return
+	}
+
+	err = s.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		group, err := qtx.GetLabelGroupByID(ctx, int32(groupId))
+		if err != nil {
+			return LabelGroupNotFoundError{}
+		}
+
+		return qtx.DeleteLabelGroupByID(ctx, group.ID)
+	})
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case LabelGroupNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete label group"})
+	}
+}
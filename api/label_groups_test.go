@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelGroups(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	for _, name := range []string{"type::bug", "type::feature", "priority::high", "priority::low", "customer"} {
+		sdk.CreateLabel(api.CreateLabelRequest{Name: name}, nil)
+	}
+
+	var groupRes api.CreateLabelGroupResponse
+	httpRes, err := sdk.CreateLabelGroup(api.CreateLabelGroupRequest{Name: "type"}, &groupRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	require.True(t, groupRes.Data.Exclusive)
+
+	exclusive := false
+	httpRes, err = sdk.CreateLabelGroup(api.CreateLabelGroupRequest{Name: "priority", Exclusive: &exclusive}, &groupRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	require.False(t, groupRes.Data.Exclusive)
+
+	t.Run("success: create keeps one label per exclusive group", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateTicketResponse
+		httpRes, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+			Labels:      []string{"type::bug", "customer", "type::feature", "priority::high", "priority::low"},
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.ElementsMatch(t, []string{"customer", "type::feature", "priority::high", "priority::low"}, res.Data.Labels)
+	})
+
+	t.Run("success: patch replaces the label of the group", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+			Labels:      []string{"type::feature", "customer"},
+		}, &res)
+		require.NoError(t, err, "error creating ticket")
+
+		var patchRes api.PatchTicketResponse
+		httpRes, err := sdk.PatchTicket(res.Data.ID, api.PatchTicketRequest{
+			Labels: []string{"type::bug", "type::feature", "customer"},
+		}, &patchRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.ElementsMatch(t, []string{"type::bug", "customer"}, patchRes.Data.Labels)
+	})
+
+	t.Run("success: search by scope", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       "scoped search",
+			Description: gofakeit.Sentence(10),
+			Labels:      []string{"priority::high"},
+		}, &res)
+		require.NoError(t, err, "error creating ticket")
+
+		var ticketsRes api.TicketsResponse
+		httpRes, err := sdk.Tickets(&ticketsRes, &url.Values{"q": []string{"scoped label:priority::*"}})
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, ticketsRes.Data, 1)
+		require.Equal(t, res.Data.ID, ticketsRes.Data[0].ID)
+
+		var labelsRes api.LabelsResponse
+		_, err = sdk.Labels(&labelsRes)
+		require.NoError(t, err, "error getting labels")
+		for _, label := range labelsRes.Data {
+			if label.Name == "priority::high" {
+				require.Equal(t, "priority", label.Scope)
+			}
+		}
+	})
+
+	t.Run("error: members can't manage label groups", func(t *testing.T) {
+		t.Parallel()
+
+		member, _ := testutil.NewMember(t, &sdk)
+		memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+		var res api.CreateLabelGroupResponse
+		httpRes, err := memberSdk.CreateLabelGroup(api.CreateLabelGroupRequest{Name: "status"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+
+		httpRes, err = memberSdk.DeleteLabelGroup(groupRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+}
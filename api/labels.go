@@ -1,16 +1,34 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/BrunoQuaresma/openticket/api/database/sqlc"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
 )
 
+const DefaultLabelColor = "#6b7280"
+
 type Label struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+func labelResponse(label sqlc.Label) Label {
+	return Label{
+		ID:          int(label.ID),
+		Name:        label.Name,
+		Color:       label.Color,
+		Description: label.Description,
+		Scope:       label.Scope.String,
+	}
 }
 
 type LabelsResponse = Response[[]Label]
@@ -24,18 +42,92 @@ func (s *Server) labels(c *gin.Context) {
 
 	var data []Label
 	for _, label := range labels {
-		data = append(data, Label{
-			ID:   int(label.ID),
-			Name: label.Name,
-		})
+		data = append(data, labelResponse(label))
 	}
 	c.JSON(http.StatusOK, LabelsResponse{
 		Data: data,
 	})
 }
 
+type LabelNotFoundError struct{}
+
+func (e LabelNotFoundError) Error() string {
+	return "label not found"
+}
+
+type LabelNameAlreadyInUseError struct{}
+
+func (e LabelNameAlreadyInUseError) Error() string {
+	return "label name already in use"
+}
+
+// UnknownLabelsError lists the labels of a request that don't exist as
+// fields like labels[1].
+type UnknownLabelsError struct {
+	Fields []string
+}
+
+func (e UnknownLabelsError) Error() string {
+	return "unknown labels"
+}
+
+func (e UnknownLabelsError) ValidationErrors() []ValidationError {
+	errors := make([]ValidationError, len(e.Fields))
+	for i, field := range e.Fields {
+		errors[i] = ValidationError{Field: field, Validator: "exists"}
+	}
+	return errors
+}
+
+// ensureLabels checks that labels exist before they are added to a ticket.
+// Missing labels are created when the auto_create_labels setting is on,
+// otherwise they are reported with an UnknownLabelsError.
+func ensureLabels(ctx context.Context, qtx *sqlc.Queries, user *sqlc.User, names []string) error {
+	settings, err := qtx.GetSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	var unknown UnknownLabelsError
+	for i, name := range names {
+		_, err := qtx.GetLabelByName(ctx, name)
+		if err == nil {
+			continue
+		}
+		if err != pgx.ErrNoRows {
+			return err
+		}
+
+		if !settings.AutoCreateLabels {
+			unknown.Fields = append(unknown.Fields, fmt.Sprintf("labels[%d]", i))
+			continue
+		}
+
+		label, err := qtx.CreateLabel(ctx, sqlc.CreateLabelParams{
+			Name:      name,
+			Color:     DefaultLabelColor,
+			CreatedBy: user.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = publishEvent(ctx, qtx, EventLabelCreated, user, labelResponse(label))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(unknown.Fields) > 0 {
+		return unknown
+	}
+	return nil
+}
+
 type CreateLabelRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name        string `json:"name" validate:"required,max=50"`
+	Color       string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=255"`
 }
 
 type CreateLabelResponse = Response[Label]
@@ -45,20 +137,175 @@ func (s *Server) createLabel(c *gin.Context) {
 
 	var req CreateLabelRequest
 	s.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	color := req.Color
+	if color == "" {
+		color = DefaultLabelColor
+	}
+
+	var label sqlc.Label
+	err := s.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetLabelByName(ctx, req.Name)
+		if err == nil {
+			return LabelNameAlreadyInUseError{}
+		}
+		if err != pgx.ErrNoRows {
+			return err
+		}
+
+		label, err = qtx.CreateLabel(ctx, sqlc.CreateLabelParams{
+			Name:        req.Name,
+			Color:       color,
+			Description: req.Description,
+			CreatedBy:   user.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return publishEvent(ctx, qtx, EventLabelCreated, user, labelResponse(label))
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateLabelResponse{Data: labelResponse(label)})
+	case LabelNameAlreadyInUseError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "name", Validator: "unique"},
+			},
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create label"})
+	}
+}
+
+type PatchLabelRequest struct {
+	Name        string  `json:"name,omitempty" validate:"omitempty,max=50"`
+	Color       string  `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+type PatchLabelResponse = Response[Label]
+
+// patchLabel updates a label in place. Tickets reference labels by id so a
+// rename shows up on every ticket that has the label.
+func (s *Server) patchLabel(c *gin.Context) {
+	user := s.AuthUserFromContext(c)
+
+	labelId, err := strconv.ParseInt(c.Param("labelId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "label not found"})
+		return
+	}
+
+	var req PatchLabelRequest
+	s.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var updatedLabel sqlc.Label
+	err = s.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		label, err := qtx.GetLabelByID(ctx, int32(labelId))
+		if err != nil {
+			return LabelNotFoundError{}
+		}
+
+		if label.CreatedBy != user.ID && user.Role != "admin" {
+			return PermissionDeniedError{Message: "only admins and the label's creator can update labels"}
+		}
+
+		params := sqlc.UpdateLabelByIDParams{
+			ID:          label.ID,
+			Name:        label.Name,
+			Color:       label.Color,
+			Description: label.Description,
+		}
+		if req.Name != "" && req.Name != label.Name {
+			_, err = qtx.GetLabelByName(ctx, req.Name)
+			if err == nil {
+				return LabelNameAlreadyInUseError{}
+			}
+			if err != pgx.ErrNoRows {
+				return err
+			}
+			params.Name = req.Name
+		}
+		if req.Color != "" {
+			params.Color = req.Color
+		}
+		if req.Description != nil {
+			params.Description = *req.Description
+		}
+
+		updatedLabel, err = qtx.UpdateLabelByID(ctx, params)
+		if err != nil {
+			return err
+		}
 
-	label, err := s.db.Queries().CreateLabel(c, sqlc.CreateLabelParams{
-		Name:      req.Name,
-		CreatedBy: user.ID,
+		return publishEvent(ctx, qtx, EventLabelUpdated, user, labelResponse(updatedLabel))
 	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusOK, PatchLabelResponse{Data: labelResponse(updatedLabel)})
+	case LabelNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	case LabelNameAlreadyInUseError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "name", Validator: "unique"},
+			},
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update label"})
+	}
+}
+
+// deleteLabel removes the label from every ticket that has it.
+func (s *Server) deleteLabel(c *gin.Context) {
+	user := s.AuthUserFromContext(c)
+
+	labelId, err := strconv.ParseInt(c.Param("labelId"), 10, 32)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create label"})
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "label not found"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, CreateLabelResponse{
-		Data: Label{
-			ID:   int(label.ID),
-			Name: label.Name,
-		},
+	err = s.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		label, err := qtx.GetLabelByID(ctx, int32(labelId))
+		if err != nil {
+			return LabelNotFoundError{}
+		}
+
+		if label.CreatedBy != user.ID && user.Role != "admin" {
+			return PermissionDeniedError{Message: "only admins and the label's creator can delete labels"}
+		}
+
+		err = qtx.DeleteLabelByID(ctx, label.ID)
+		if err != nil {
+			return err
+		}
+
+		return publishEvent(ctx, qtx, EventLabelDeleted, user, labelResponse(label))
 	})
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case LabelNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete label"})
+	}
 }
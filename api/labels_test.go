@@ -0,0 +1,141 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	createLabel := func(t *testing.T, req api.CreateLabelRequest) api.Label {
+		var res api.CreateLabelResponse
+		httpRes, err := sdk.CreateLabel(req, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		return res.Data
+	}
+
+	t.Run("success: create with defaults", func(t *testing.T) {
+		t.Parallel()
+
+		label := createLabel(t, api.CreateLabelRequest{Name: gofakeit.UUID()})
+		require.Equal(t, api.DefaultLabelColor, label.Color)
+		require.Empty(t, label.Description)
+	})
+
+	t.Run("success: rename propagates to tickets", func(t *testing.T) {
+		t.Parallel()
+
+		label := createLabel(t, api.CreateLabelRequest{
+			Name:        gofakeit.UUID(),
+			Color:       "#d73a4a",
+			Description: "Something isn't working",
+		})
+
+		var ticketRes api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+			Labels:      []string{label.Name},
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		newName := gofakeit.UUID()
+		var patchRes api.PatchLabelResponse
+		httpRes, err := sdk.PatchLabel(label.ID, api.PatchLabelRequest{Name: newName}, &patchRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Equal(t, newName, patchRes.Data.Name)
+		require.Equal(t, "#d73a4a", patchRes.Data.Color)
+		require.Equal(t, "Something isn't working", patchRes.Data.Description)
+
+		var ticket api.TicketResponse
+		_, err = sdk.Ticket(ticketRes.Data.ID, &ticket)
+		require.NoError(t, err, "error getting ticket")
+		require.Equal(t, []string{newName}, ticket.Data.Labels)
+	})
+
+	t.Run("success: delete removes the label from tickets", func(t *testing.T) {
+		t.Parallel()
+
+		label := createLabel(t, api.CreateLabelRequest{Name: gofakeit.UUID()})
+
+		var ticketRes api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+			Labels:      []string{label.Name},
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		httpRes, err := sdk.DeleteLabel(label.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+		var ticket api.TicketResponse
+		_, err = sdk.Ticket(ticketRes.Data.ID, &ticket)
+		require.NoError(t, err, "error getting ticket")
+		require.Empty(t, ticket.Data.Labels)
+
+		httpRes, err = sdk.DeleteLabel(label.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("error: duplicate name", func(t *testing.T) {
+		t.Parallel()
+
+		label := createLabel(t, api.CreateLabelRequest{Name: gofakeit.UUID()})
+
+		var res api.CreateLabelResponse
+		httpRes, err := sdk.CreateLabel(api.CreateLabelRequest{Name: label.Name}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "name", "unique")
+
+		other := createLabel(t, api.CreateLabelRequest{Name: gofakeit.UUID()})
+		var patchRes api.PatchLabelResponse
+		httpRes, err = sdk.PatchLabel(other.ID, api.PatchLabelRequest{Name: label.Name}, &patchRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, patchRes.Errors, "name", "unique")
+	})
+
+	t.Run("error: invalid color", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateLabelResponse
+		httpRes, err := sdk.CreateLabel(api.CreateLabelRequest{Name: gofakeit.UUID(), Color: "red"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "color", "hexcolor")
+	})
+
+	t.Run("error: members can't change labels of others", func(t *testing.T) {
+		t.Parallel()
+
+		label := createLabel(t, api.CreateLabelRequest{Name: gofakeit.UUID()})
+		member, _ := testutil.NewMember(t, &sdk)
+		memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+		var res api.PatchLabelResponse
+		httpRes, err := memberSdk.PatchLabel(label.ID, api.PatchLabelRequest{Color: "#000000"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+
+		httpRes, err = memberSdk.DeleteLabel(label.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+}
@@ -0,0 +1,121 @@
+package mailer_test
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api/mailer"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type templateData struct {
+	RecipientName  string
+	ActorName      string
+	ActorUsername  string
+	TicketID       int32
+	TicketTitle    string
+	TicketStatus   string
+	TicketURL      string
+	CommentContent string
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	data := templateData{
+		RecipientName:  "Ada",
+		ActorName:      "Grace",
+		ActorUsername:  "grace",
+		TicketID:       42,
+		TicketTitle:    "Printer is on fire",
+		TicketStatus:   "closed",
+		TicketURL:      "http://openticket.test/tickets/42",
+		CommentContent: "<script>alert(1)</script>",
+	}
+
+	for _, event := range []string{"mention", "assignment", "comment", "status_change"} {
+		t.Run(event, func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := mailer.Render(event, "ada@openticket.test", data)
+			require.NoError(t, err, "error rendering template")
+			require.Contains(t, msg.Subject, "[#42]")
+			require.Contains(t, msg.Text, "Hi Ada,")
+			require.Contains(t, msg.Text, data.TicketURL)
+			require.Contains(t, msg.HTML, `href="http://openticket.test/tickets/42"`)
+			require.NotContains(t, msg.HTML, "<script>")
+		})
+	}
+}
+
+func TestMailer_Send(t *testing.T) {
+	t.Parallel()
+
+	fakeSMTP := testutil.NewFakeSMTP(t)
+	m := mailer.New(fakeSMTP.Config())
+
+	err := m.Send(context.Background(), mailer.Message{
+		To:      "Ada <ada@openticket.test>",
+		Subject: "Olá from openticket",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	})
+	require.NoError(t, err, "error sending message")
+
+	messages := fakeSMTP.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, "support@openticket.test", messages[0].From)
+	require.Equal(t, []string{"ada@openticket.test"}, messages[0].To)
+
+	msg := messages[0].Parse(t)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err, "error decoding subject")
+	require.Equal(t, "Olá from openticket", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err, "error parsing content type")
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	var bodies []string
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "error reading part")
+		b, err := io.ReadAll(part)
+		require.NoError(t, err, "error reading part")
+		bodies = append(bodies, strings.TrimSpace(string(b)))
+	}
+	require.Equal(t, []string{"plain body", "<p>html body</p>"}, bodies)
+
+	fakeSMTP.FailNext(1)
+	err = m.Send(context.Background(), mailer.Message{To: "ada@openticket.test", Subject: "retry"})
+	require.Error(t, err)
+}
+
+func TestMailer_SendThreadToken(t *testing.T) {
+	t.Parallel()
+
+	fakeSMTP := testutil.NewFakeSMTP(t)
+	m := mailer.New(fakeSMTP.Config())
+
+	err := m.Send(context.Background(), mailer.Message{
+		To:          "ada@openticket.test",
+		Subject:     "Printer is on fire",
+		Text:        "plain body",
+		HTML:        "<p>html body</p>",
+		ThreadToken: "abc123",
+	})
+	require.NoError(t, err, "error sending message")
+
+	msg := fakeSMTP.Messages()[0].Parse(t)
+	require.Equal(t, `"Openticket" <support+abc123@openticket.test>`, msg.Header.Get("Reply-To"))
+	require.True(t, strings.HasPrefix(msg.Header.Get("Message-ID"), "<abc123."))
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templatesFS, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templatesFS, "templates/*.html"))
+)
+
+// Render builds a message from the templates of an event type. The plain-text
+// template of each event defines the subject in a "subject" block.
+func Render(event string, to string, data any) (Message, error) {
+	var subject, text, html bytes.Buffer
+
+	err := textTemplates.ExecuteTemplate(&subject, event+".subject", data)
+	if err != nil {
+		return Message{}, err
+	}
+	err = textTemplates.ExecuteTemplate(&text, event+".txt", data)
+	if err != nil {
+		return Message{}, err
+	}
+	err = htmlTemplates.ExecuteTemplate(&html, event+".html", data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, references{}),
+	// Raw HTML is kept so harmless markup survives, the policy below takes
+	// care of everything else.
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^(mention|ticket-reference)$`)).OnElements("a", "span")
+	p.AllowAttrs("data-username").OnElements("span")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render converts CommonMark with GitHub Flavored Markdown extensions to
+// sanitized HTML. #123 becomes a link to the ticket and @username a mention,
+// but only for the given usernames so unknown handles stay plain text.
+func Render(source string, mentions ...string) string {
+	ctx := parser.NewContext()
+	known := make(map[string]bool, len(mentions))
+	for _, username := range mentions {
+		known[username] = true
+	}
+	ctx.Set(mentionsKey, known)
+
+	var buf bytes.Buffer
+	err := md.Convert([]byte(source), &buf, parser.WithContext(ctx))
+	if err != nil {
+		// Writing to a buffer doesn't fail, but never return unsanitized
+		// content if it does.
+		return policy.Sanitize(source)
+	}
+	return strings.TrimSpace(policy.Sanitize(buf.String()))
+}
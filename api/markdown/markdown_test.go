@@ -0,0 +1,77 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api/markdown"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		source   string
+		mentions []string
+		want     string
+	}{
+		{
+			name:   "commonmark",
+			source: "**bold** and `code`",
+			want:   "<p><strong>bold</strong> and <code>code</code></p>",
+		},
+		{
+			name:   "task list",
+			source: "- [x] reproduce\n- [ ] fix",
+			want: "<ul>\n" +
+				`<li><input checked="" disabled="" type="checkbox"> reproduce</li>` + "\n" +
+				`<li><input disabled="" type="checkbox"> fix</li>` + "\n" +
+				"</ul>",
+		},
+		{
+			name:   "strikethrough and autolink",
+			source: "~~old~~ see https://example.com",
+			want:   `<p><del>old</del> see <a href="https://example.com" rel="nofollow">https://example.com</a></p>`,
+		},
+		{
+			name:   "ticket reference",
+			source: "Duplicate of #42.",
+			want:   `<p>Duplicate of <a href="/tickets/42" class="ticket-reference" rel="nofollow">#42</a>.</p>`,
+		},
+		{
+			name:   "ticket reference inside a word",
+			source: "See page#42 and `#42`",
+			want:   "<p>See page#42 and <code>#42</code></p>",
+		},
+		{
+			name:     "mention",
+			source:   "Thanks @ada.",
+			mentions: []string{"ada"},
+			want:     `<p>Thanks <span class="mention" data-username="ada">@ada</span>.</p>`,
+		},
+		{
+			name:     "unknown mention",
+			source:   "Thanks @grace and ada@example.com",
+			mentions: []string{"ada"},
+			want:     `<p>Thanks @grace and <a href="mailto:ada@example.com" rel="nofollow">ada@example.com</a></p>`,
+		},
+		{
+			name:   "script",
+			source: "hello <script>alert(1)</script> <a href=\"javascript:alert(1)\" onclick=\"x()\">link</a>",
+			want:   "<p>hello  link</p>",
+		},
+		{
+			name:   "heading",
+			source: "# Steps",
+			want:   "<h1>Steps</h1>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, markdown.Render(tt.source, tt.mentions...))
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// TicketURL is the page #123 references link to.
+const TicketURL = "/tickets/%d"
+
+var (
+	ticketReferenceRegexp = regexp.MustCompile(`^#(\d+)\b`)
+	mentionRegexp         = regexp.MustCompile(`^@([\w.-]+)`)
+)
+
+var mentionsKey = parser.NewContextKey()
+
+var KindMention = ast.NewNodeKind("Mention")
+
+type Mention struct {
+	ast.BaseInline
+	Username string
+}
+
+func (n *Mention) Kind() ast.NodeKind {
+	return KindMention
+}
+
+func (n *Mention) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Username": n.Username}, nil)
+}
+
+// references is a goldmark extension for #123 ticket references and
+// @username mentions.
+type references struct{}
+
+func (references) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(referenceParser{}, 999)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(mentionRenderer{}, 999)))
+}
+
+type referenceParser struct{}
+
+func (referenceParser) Trigger() []byte {
+	return []byte{'#', '@'}
+}
+
+func (referenceParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	// References must start a word, so emails and anchors like a#1 are left
+	// alone.
+	before := block.PrecendingCharacter()
+	if unicode.IsLetter(before) || unicode.IsDigit(before) || before == '_' || before == '#' || before == '@' || before == '/' {
+		return nil
+	}
+
+	line, segment := block.PeekLine()
+	switch line[0] {
+	case '#':
+		match := ticketReferenceRegexp.FindSubmatch(line)
+		if match == nil {
+			return nil
+		}
+		id, err := strconv.Atoi(string(match[1]))
+		if err != nil {
+			return nil
+		}
+
+		link := ast.NewLink()
+		link.Destination = []byte(fmt.Sprintf(TicketURL, id))
+		link.SetAttributeString("class", []byte("ticket-reference"))
+		link.AppendChild(link, ast.NewTextSegment(segment.WithStop(segment.Start+len(match[0]))))
+		block.Advance(len(match[0]))
+		return link
+	case '@':
+		match := mentionRegexp.FindSubmatch(line)
+		if match == nil {
+			return nil
+		}
+		username := strings.TrimRight(string(match[1]), ".-")
+		known, _ := pc.Get(mentionsKey).(map[string]bool)
+		if !known[username] {
+			return nil
+		}
+
+		mention := &Mention{Username: username}
+		mention.AppendChild(mention, ast.NewTextSegment(segment.WithStop(segment.Start+1+len(username))))
+		block.Advance(1 + len(username))
+		return mention
+	}
+	return nil
+}
+
+type mentionRenderer struct{}
+
+func (r mentionRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindMention, r.renderMention)
+}
+
+func (mentionRenderer) renderMention(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		mention := node.(*Mention)
+		fmt.Fprintf(w, `<span class="mention" data-username="%s">`, html.EscapeString(mention.Username))
+	} else {
+		w.WriteString("</span>")
+	}
+	return ast.WalkContinue, nil
+}
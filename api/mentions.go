@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]+)`)
+
+// parseMentions returns the unique usernames mentioned in content, in the
+// order they first appear.
+func parseMentions(content string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, match := range mentionRegexp.FindAllStringSubmatch(content, -1) {
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// saveMentions syncs the mention records of a comment with its content and
+// notifies users that were mentioned for the first time. Unknown usernames
+// are ignored.
+func saveMentions(ctx context.Context, qtx *sqlc.Queries, comment sqlc.Comment, author *sqlc.User) ([]sqlc.User, error) {
+	var mentioned []sqlc.User
+	userIDs := []int32{}
+	for _, username := range parseMentions(comment.Content) {
+		user, err := qtx.GetUserByUsername(ctx, username)
+		if err != nil {
+			continue
+		}
+		if comment.Visibility == sqlc.CommentVisibilityInternal && !canViewInternalComments(&user) {
+			continue
+		}
+
+		created, err := qtx.CreateCommentMention(ctx, sqlc.CreateCommentMentionParams{
+			CommentID: comment.ID,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if created > 0 {
+			err = watch(ctx, qtx, comment.TicketID, user.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			err = notify(ctx, qtx, NotificationEvent{
+				Type:      sqlc.NotificationTypeMention,
+				TicketID:  comment.TicketID,
+				CommentID: pgtype.Int4{Int32: comment.ID, Valid: true},
+				ActorID:   author.ID,
+			}, user.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		mentioned = append(mentioned, user)
+		userIDs = append(userIDs, user.ID)
+	}
+
+	err := qtx.DeleteCommentMentionsExcept(ctx, sqlc.DeleteCommentMentionsExceptParams{
+		CommentID: comment.ID,
+		UserIds:   userIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mentioned, nil
+}
+
+func mentionsResponse(users []sqlc.User) []User {
+	mentions := make([]User, len(users))
+	for i, user := range users {
+		mentions[i] = userResponse(user)
+	}
+	return mentions
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Notification struct {
+	ID          int32  `json:"id"`
+	Type        string `json:"type"`
+	TicketID    int32  `json:"ticket_id"`
+	TicketTitle string `json:"ticket_title"`
+	CommentID   int32  `json:"comment_id,omitempty"`
+	Actor       User   `json:"actor"`
+	Read        bool   `json:"read"`
+	ReadAt      string `json:"read_at,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type NotificationEvent struct {
+	Type      sqlc.NotificationType
+	TicketID  int32
+	CommentID pgtype.Int4
+	ActorID   int32
+}
+
+// notify records the event in the inbox of each recipient. The actor is never
+// notified about their own actions and duplicated recipients are notified
+// once.
+func notify(ctx context.Context, qtx *sqlc.Queries, event NotificationEvent, recipientIDs ...int32) error {
+	var notified []int32
+	for _, recipientID := range recipientIDs {
+		if recipientID == event.ActorID || slices.Contains(notified, recipientID) {
+			continue
+		}
+		notified = append(notified, recipientID)
+
+		_, err := qtx.CreateNotification(ctx, sqlc.CreateNotificationParams{
+			UserID:    recipientID,
+			Type:      event.Type,
+			TicketID:  event.TicketID,
+			CommentID: event.CommentID,
+			ActorID:   event.ActorID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyTeamAssignment notifies every member of a team that was assigned to a
+// ticket.
+func notifyTeamAssignment(ctx context.Context, qtx *sqlc.Queries, ticketID int32, teamID int32, actorID int32) error {
+	members, err := qtx.GetTeamMembers(ctx, teamID)
+	if err != nil {
+		return err
+	}
+	memberIDs := make([]int32, len(members))
+	for i, member := range members {
+		memberIDs[i] = member.User.ID
+	}
+	return notify(ctx, qtx, NotificationEvent{
+		Type:     sqlc.NotificationTypeAssignment,
+		TicketID: ticketID,
+		ActorID:  actorID,
+	}, memberIDs...)
+}
+
+type NotificationsResponse = Response[[]Notification]
+
+func (server *Server) notifications(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	unread, err := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Errors: []ValidationError{{Field: "unread", Validator: "boolean"}},
+		})
+		return
+	}
+
+	notificationRows, err := server.db.Queries().GetNotificationsByUserID(c, sqlc.GetNotificationsByUserIDParams{
+		UserID: user.ID,
+		Unread: unread,
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get notifications"})
+		return
+	}
+
+	notifications := make([]Notification, len(notificationRows))
+	for i, notification := range notificationRows {
+		var readAt string
+		if notification.ReadAt.Valid {
+			readAt = notification.ReadAt.Time.Format(time.RFC3339)
+		}
+		notifications[i] = Notification{
+			ID:          notification.ID,
+			Type:        string(notification.Type),
+			TicketID:    notification.TicketID,
+			TicketTitle: notification.TicketTitle,
+			CommentID:   notification.CommentID.Int32,
+			Read:        notification.ReadAt.Valid,
+			ReadAt:      readAt,
+			CreatedAt:   notification.CreatedAt.Time.Format(time.RFC3339),
+			Actor: User{
+				ID:       notification.User.ID,
+				Name:     notification.User.Name,
+				Username: notification.User.Username,
+				Email:    notification.User.Email,
+				Role:     string(notification.User.Role),
+			},
+		}
+	}
+
+	c.JSON(http.StatusOK, NotificationsResponse{Data: notifications})
+}
+
+func (server *Server) markNotificationAsRead(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	notificationId, err := strconv.ParseInt(c.Param("notificationId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "notification not found"})
+		return
+	}
+
+	_, err = server.db.Queries().MarkNotificationAsRead(c, sqlc.MarkNotificationAsReadParams{
+		ID:     int32(notificationId),
+		UserID: user.ID,
+	})
+	if err == pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "notification not found"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to mark notification as read"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (server *Server) markAllNotificationsAsRead(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	err := server.db.Queries().MarkAllNotificationsAsRead(c, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to mark notifications as read"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Notifications(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, memberRes := testutil.NewMember(t, &sdk)
+	memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+		AssignedTo:  []int32{memberRes.Data.ID},
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	var memberTicketRes api.CreateTicketResponse
+	_, err = memberSdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+	}, &memberTicketRes)
+	require.NoError(t, err, "error creating ticket")
+
+	var statusRes api.PatchTicketStatusResponse
+	_, err = sdk.PatchTicketStatus(memberTicketRes.Data.ID, api.PatchTicketStatusRequest{Status: "closed"}, &statusRes)
+	require.NoError(t, err, "error updating ticket status")
+
+	var commentRes api.CreateCommentResponse
+	_, err = memberSdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: "I'm taking a look at this one.",
+	}, &commentRes)
+	require.NoError(t, err, "error creating comment")
+
+	var res api.NotificationsResponse
+	httpRes, err := memberSdk.Notifications(&res, nil)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.Len(t, res.Data, 2)
+	require.Equal(t, "status_change", res.Data[0].Type)
+	require.Equal(t, "assignment", res.Data[1].Type)
+	require.Equal(t, ticketRes.Data.ID, res.Data[1].TicketID)
+	require.Equal(t, setup.Res().Data.ID, res.Data[1].Actor.ID)
+	require.False(t, res.Data[1].Read)
+
+	var adminRes api.NotificationsResponse
+	_, err = sdk.Notifications(&adminRes, nil)
+	require.NoError(t, err, "error making request")
+	require.Len(t, adminRes.Data, 1)
+	require.Equal(t, "comment", adminRes.Data[0].Type)
+	require.Equal(t, commentRes.Data.ID, adminRes.Data[0].CommentID)
+
+	t.Run("error: can't mark other users notifications", func(t *testing.T) {
+		httpRes, err := sdk.MarkNotificationAsRead(res.Data[0].ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("success: mark as read", func(t *testing.T) {
+		httpRes, err := memberSdk.MarkNotificationAsRead(res.Data[0].ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+		urlValues := url.Values{"unread": []string{"true"}}
+		var unreadRes api.NotificationsResponse
+		_, err = memberSdk.Notifications(&unreadRes, &urlValues)
+		require.NoError(t, err, "error making request")
+		require.Len(t, unreadRes.Data, 1)
+		require.Equal(t, res.Data[1].ID, unreadRes.Data[0].ID)
+	})
+
+	t.Run("success: mark all as read", func(t *testing.T) {
+		httpRes, err := memberSdk.MarkAllNotificationsAsRead()
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+		urlValues := url.Values{"unread": []string{"true"}}
+		var unreadRes api.NotificationsResponse
+		_, err = memberSdk.Notifications(&unreadRes, &urlValues)
+		require.NoError(t, err, "error making request")
+		require.Empty(t, unreadRes.Data)
+	})
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+var ReactionEmojis = []string{"+1", "-1", "laugh", "confused", "heart", "hooray", "rocket", "eyes"}
+
+type CreateReactionRequest struct {
+	Emoji string `json:"emoji" validate:"required,oneof=+1 -1 laugh confused heart hooray rocket eyes"`
+}
+
+type Reaction struct {
+	Emoji       string `json:"emoji"`
+	Count       int64  `json:"count"`
+	ReactedByMe bool   `json:"reacted_by_me"`
+}
+
+type ReactionsResponse = Response[[]Reaction]
+
+// ticketReactions aggregates the reactions of the tickets, flagging the ones
+// left by the user.
+func ticketReactions(ctx context.Context, q *sqlc.Queries, userID int32, ticketIDs ...int32) (map[int32][]Reaction, error) {
+	rows, err := q.GetTicketReactions(ctx, sqlc.GetTicketReactionsParams{
+		UserID:    userID,
+		TicketIds: ticketIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reactions := make(map[int32][]Reaction)
+	for _, row := range rows {
+		reactions[row.TicketID] = append(reactions[row.TicketID], Reaction{
+			Emoji:       row.Emoji,
+			Count:       row.Count,
+			ReactedByMe: row.ReactedByMe,
+		})
+	}
+	return reactions, nil
+}
+
+// commentReactions aggregates the reactions of the comments, flagging the ones
+// left by the user.
+func commentReactions(ctx context.Context, q *sqlc.Queries, userID int32, commentIDs ...int32) (map[int32][]Reaction, error) {
+	rows, err := q.GetCommentReactions(ctx, sqlc.GetCommentReactionsParams{
+		UserID:     userID,
+		CommentIds: commentIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reactions := make(map[int32][]Reaction)
+	for _, row := range rows {
+		reactions[row.CommentID] = append(reactions[row.CommentID], Reaction{
+			Emoji:       row.Emoji,
+			Count:       row.Count,
+			ReactedByMe: row.ReactedByMe,
+		})
+	}
+	return reactions, nil
+}
+
+func (server *Server) createTicketReaction(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	var req CreateReactionRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var reactions map[int32][]Reaction
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return TicketNotFoundError{}
+		}
+
+		err = qtx.AddTicketReaction(ctx, sqlc.AddTicketReactionParams{
+			TicketID: int32(ticketId),
+			UserID:   user.ID,
+			Emoji:    req.Emoji,
+		})
+		if err != nil {
+			return err
+		}
+
+		reactions, err = ticketReactions(ctx, qtx, user.ID, int32(ticketId))
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, ReactionsResponse{Data: reactions[int32(ticketId)]})
+	case TicketNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create reaction"})
+	}
+}
+
+func (server *Server) deleteTicketReaction(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	emoji := c.Param("emoji")
+	if !slices.Contains(ReactionEmojis, emoji) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "invalid emoji",
+			Errors:  []ValidationError{{Field: "emoji", Validator: "oneof"}},
+		})
+		return
+	}
+
+	err = server.db.Queries().RemoveTicketReaction(c, sqlc.RemoveTicketReactionParams{
+		TicketID: int32(ticketId),
+		UserID:   user.ID,
+		Emoji:    emoji,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete reaction"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// reactableComment returns the comment if the user can see it. Reacting to a
+// comment the user can't read is reported as not found.
+func reactableComment(ctx context.Context, qtx *sqlc.Queries, user *sqlc.User, ticketID int32, commentID int32) (sqlc.Comment, error) {
+	comment, err := qtx.GetCommentByID(ctx, commentID)
+	if err != nil || comment.TicketID != ticketID || comment.DeletedAt.Valid {
+		return sqlc.Comment{}, CommentNotFoundError{}
+	}
+	if comment.Visibility == sqlc.CommentVisibilityInternal && !canViewInternalComments(user) {
+		return sqlc.Comment{}, CommentNotFoundError{}
+	}
+	return comment, nil
+}
+
+func (server *Server) createCommentReaction(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
+	commentId, err := strconv.ParseInt(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
+	var req CreateReactionRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var reactions map[int32][]Reaction
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		comment, err := reactableComment(ctx, qtx, user, int32(ticketId), int32(commentId))
+		if err != nil {
+			return err
+		}
+
+		err = qtx.AddCommentReaction(ctx, sqlc.AddCommentReactionParams{
+			CommentID: comment.ID,
+			UserID:    user.ID,
+			Emoji:     req.Emoji,
+		})
+		if err != nil {
+			return err
+		}
+
+		reactions, err = commentReactions(ctx, qtx, user.ID, comment.ID)
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, ReactionsResponse{Data: reactions[int32(commentId)]})
+	case CommentNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create reaction"})
+	}
+}
+
+func (server *Server) deleteCommentReaction(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	commentId, err := strconv.ParseInt(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "comment not found"})
+		return
+	}
+
+	emoji := c.Param("emoji")
+	if !slices.Contains(ReactionEmojis, emoji) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "invalid emoji",
+			Errors:  []ValidationError{{Field: "emoji", Validator: "oneof"}},
+		})
+		return
+	}
+
+	err = server.db.Queries().RemoveCommentReaction(c, sqlc.RemoveCommentReactionParams{
+		CommentID: int32(commentId),
+		UserID:    user.ID,
+		Emoji:     emoji,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete reaction"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,176 @@
+package api_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Reactions(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, _ := testutil.NewMember(t, &sdk)
+	memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+	createTicket := func(t *testing.T) api.Ticket {
+		var ticketRes api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+		return ticketRes.Data
+	}
+
+	t.Run("success: ticket reactions", func(t *testing.T) {
+		ticket := createTicket(t)
+
+		var res api.ReactionsResponse
+		httpRes, err := sdk.CreateTicketReaction(ticket.ID, api.CreateReactionRequest{Emoji: "+1"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.Equal(t, []api.Reaction{{Emoji: "+1", Count: 1, ReactedByMe: true}}, res.Data)
+
+		// Reacting twice with the same emoji is a no-op.
+		_, err = sdk.CreateTicketReaction(ticket.ID, api.CreateReactionRequest{Emoji: "+1"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, int64(1), res.Data[0].Count)
+
+		_, err = memberSdk.CreateTicketReaction(ticket.ID, api.CreateReactionRequest{Emoji: "+1"}, &res)
+		require.NoError(t, err, "error making request")
+		_, err = memberSdk.CreateTicketReaction(ticket.ID, api.CreateReactionRequest{Emoji: "eyes"}, &res)
+		require.NoError(t, err, "error making request")
+
+		var ticketRes api.TicketResponse
+		_, err = sdk.Ticket(ticket.ID, &ticketRes)
+		require.NoError(t, err, "error getting ticket")
+		require.Equal(t, []api.Reaction{
+			{Emoji: "+1", Count: 2, ReactedByMe: true},
+			{Emoji: "eyes", Count: 1, ReactedByMe: false},
+		}, ticketRes.Data.Reactions)
+
+		httpRes, err = sdk.DeleteTicketReaction(ticket.ID, "+1")
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+		_, err = memberSdk.Ticket(ticket.ID, &ticketRes)
+		require.NoError(t, err, "error getting ticket")
+		require.Equal(t, []api.Reaction{
+			{Emoji: "+1", Count: 1, ReactedByMe: true},
+			{Emoji: "eyes", Count: 1, ReactedByMe: true},
+		}, ticketRes.Data.Reactions)
+	})
+
+	t.Run("success: comment reactions", func(t *testing.T) {
+		ticket := createTicket(t)
+
+		var commentRes api.CreateCommentResponse
+		_, err := sdk.CreateComment(ticket.ID, api.CreateCommentRequest{
+			Content: "A fix is on its way to production.",
+		}, &commentRes)
+		require.NoError(t, err, "error creating comment")
+
+		var res api.ReactionsResponse
+		httpRes, err := memberSdk.CreateCommentReaction(ticket.ID, commentRes.Data.ID, api.CreateReactionRequest{Emoji: "hooray"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		var commentsRes api.CommentsResponse
+		_, err = sdk.Comments(ticket.ID, &commentsRes, nil)
+		require.NoError(t, err, "error getting comments")
+		require.Len(t, commentsRes.Data, 2)
+		require.Empty(t, commentsRes.Data[0].Reactions)
+		require.Equal(t, []api.Reaction{{Emoji: "hooray", Count: 1, ReactedByMe: false}}, commentsRes.Data[1].Reactions)
+
+		httpRes, err = memberSdk.DeleteCommentReaction(ticket.ID, commentRes.Data.ID, "hooray")
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+		_, err = sdk.Comments(ticket.ID, &commentsRes, nil)
+		require.NoError(t, err, "error getting comments")
+		require.Empty(t, commentsRes.Data[1].Reactions)
+	})
+
+	t.Run("success: sort tickets by reactions", func(t *testing.T) {
+		first := createTicket(t)
+		second := createTicket(t)
+		third := createTicket(t)
+
+		var res api.ReactionsResponse
+		_, err := sdk.CreateTicketReaction(second.ID, api.CreateReactionRequest{Emoji: "+1"}, &res)
+		require.NoError(t, err, "error making request")
+		_, err = memberSdk.CreateTicketReaction(second.ID, api.CreateReactionRequest{Emoji: "+1"}, &res)
+		require.NoError(t, err, "error making request")
+		_, err = sdk.CreateTicketReaction(third.ID, api.CreateReactionRequest{Emoji: "heart"}, &res)
+		require.NoError(t, err, "error making request")
+
+		var ticketsRes api.TicketsResponse
+		httpRes, err := sdk.Tickets(&ticketsRes, &url.Values{"sort": []string{api.TicketsSortReactions}})
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+
+		var ids []int32
+		for _, ticket := range ticketsRes.Data {
+			if ticket.ID == first.ID || ticket.ID == second.ID || ticket.ID == third.ID {
+				ids = append(ids, ticket.ID)
+			}
+		}
+		require.Equal(t, []int32{second.ID, third.ID, first.ID}, ids)
+	})
+
+	t.Run("error: invalid emoji", func(t *testing.T) {
+		ticket := createTicket(t)
+
+		var res api.ReactionsResponse
+		httpRes, err := sdk.CreateTicketReaction(ticket.ID, api.CreateReactionRequest{Emoji: "shrug"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "emoji", "oneof")
+	})
+
+	t.Run("error: invalid sort", func(t *testing.T) {
+		var res api.TicketsResponse
+		httpRes, err := sdk.Tickets(&res, &url.Values{"sort": []string{"votes"}})
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "sort", "oneof")
+	})
+
+	t.Run("error: internal comment as requester", func(t *testing.T) {
+		ticket := createTicket(t)
+
+		requester := api.CreateUserRequest{
+			Name:     gofakeit.Name(),
+			Username: gofakeit.Username(),
+			Email:    gofakeit.Email(),
+			Password: testutil.FakePassword(),
+			Role:     "requester",
+		}
+		var requesterRes api.CreateUserResponse
+		_, err := sdk.CreateUser(requester, &requesterRes)
+		require.NoError(t, err, "error creating requester")
+		requesterSdk := tEnv.AuthSDK(requester.Email, requester.Password)
+
+		var commentRes api.CreateCommentResponse
+		_, err = sdk.CreateComment(ticket.ID, api.CreateCommentRequest{
+			Content:    "Internal note about this ticket.",
+			Visibility: "internal",
+		}, &commentRes)
+		require.NoError(t, err, "error creating comment")
+
+		var res api.ReactionsResponse
+		httpRes, err := requesterSdk.CreateCommentReaction(ticket.ID, commentRes.Data.ID, api.CreateReactionRequest{Emoji: "eyes"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+}
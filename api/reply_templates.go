@@ -0,0 +1,310 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+)
+
+var replyTemplateVariableRegexp = regexp.MustCompile(`\{\{\s*([\w.]+)\s*\}\}`)
+
+// ReplyTemplateVariables are the placeholders available in reply templates.
+// The requester is the user who opened the ticket and the agent is the user
+// writing the reply.
+var ReplyTemplateVariables = []string{
+	"ticket.id",
+	"ticket.title",
+	"ticket.status",
+	"requester.name",
+	"requester.username",
+	"requester.email",
+	"agent.name",
+	"agent.username",
+	"agent.email",
+}
+
+func validateReplyTemplate(fl validator.FieldLevel) bool {
+	for _, match := range replyTemplateVariableRegexp.FindAllStringSubmatch(fl.Field().String(), -1) {
+		if !slices.Contains(ReplyTemplateVariables, match[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func renderReplyTemplate(content string, ticket sqlc.GetTicketByIDRow, agent *sqlc.User) string {
+	values := map[string]string{
+		"ticket.id":          strconv.Itoa(int(ticket.ID)),
+		"ticket.title":       ticket.Title,
+		"ticket.status":      string(ticket.Status),
+		"requester.name":     ticket.User.Name,
+		"requester.username": ticket.User.Username,
+		"requester.email":    ticket.User.Email,
+		"agent.name":         agent.Name,
+		"agent.username":     agent.Username,
+		"agent.email":        agent.Email,
+	}
+	return replyTemplateVariableRegexp.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := replyTemplateVariableRegexp.FindStringSubmatch(placeholder)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// Templates are visible to their author and, when shared, to all staff.
+func canUseReplyTemplate(user *sqlc.User, template sqlc.ReplyTemplate) bool {
+	if user.Role == sqlc.RoleRequester {
+		return false
+	}
+	return template.Shared || template.CreatedBy == user.ID
+}
+
+// Shared templates are managed by admins, personal ones by their author.
+func canManageReplyTemplate(user *sqlc.User, template sqlc.ReplyTemplate) bool {
+	if template.Shared {
+		return user.Role == sqlc.RoleAdmin
+	}
+	return template.CreatedBy == user.ID
+}
+
+type ReplyTemplate struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	Content   string `json:"content"`
+	Shared    bool   `json:"shared"`
+	CreatedBy int32  `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func replyTemplateResponse(template sqlc.ReplyTemplate) ReplyTemplate {
+	return ReplyTemplate{
+		ID:        template.ID,
+		Name:      template.Name,
+		Content:   template.Content,
+		Shared:    template.Shared,
+		CreatedBy: template.CreatedBy,
+		CreatedAt: template.CreatedAt.Time.Format(time.RFC3339),
+		UpdatedAt: template.UpdatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+type ReplyTemplateNotFoundError struct{}
+
+func (e ReplyTemplateNotFoundError) Error() string {
+	return "reply template not found"
+}
+
+// InvalidRenderedTemplateError is returned when a template renders to content
+// that would be rejected if it was written by hand.
+type InvalidRenderedTemplateError struct {
+	Validator string
+}
+
+func (e InvalidRenderedTemplateError) Error() string {
+	return "the rendered reply template is not a valid comment"
+}
+
+type ReplyTemplatesResponse = Response[[]ReplyTemplate]
+
+func (server *Server) replyTemplates(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role == sqlc.RoleRequester {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins and members can use reply templates"})
+		return
+	}
+
+	templates, err := server.db.Queries().GetReplyTemplates(c, user.ID)
+	if err != nil && err != pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get reply templates"})
+		return
+	}
+
+	data := make([]ReplyTemplate, len(templates))
+	for i, template := range templates {
+		data[i] = replyTemplateResponse(template)
+	}
+	c.JSON(http.StatusOK, ReplyTemplatesResponse{Data: data})
+}
+
+type CreateReplyTemplateRequest struct {
+	Name    string `json:"name" validate:"required,min=2,max=70"`
+	Content string `json:"content" validate:"required,min=10,reply_template"`
+	Shared  bool   `json:"shared,omitempty"`
+}
+
+type CreateReplyTemplateResponse = Response[ReplyTemplate]
+
+func (server *Server) createReplyTemplate(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role == sqlc.RoleRequester {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins and members can use reply templates"})
+		return
+	}
+
+	var req CreateReplyTemplateRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	if req.Shared && user.Role != sqlc.RoleAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can create shared reply templates"})
+		return
+	}
+
+	template, err := server.db.Queries().CreateReplyTemplate(c, sqlc.CreateReplyTemplateParams{
+		Name:      req.Name,
+		Content:   req.Content,
+		Shared:    req.Shared,
+		CreatedBy: user.ID,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create reply template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateReplyTemplateResponse{Data: replyTemplateResponse(template)})
+}
+
+type ReplyTemplateResponse = Response[ReplyTemplate]
+
+func (server *Server) replyTemplate(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	templateId, err := strconv.ParseInt(c.Param("templateId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "reply template not found"})
+		return
+	}
+
+	template, err := server.db.Queries().GetReplyTemplateByID(c, int32(templateId))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "reply template not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get reply template"})
+		return
+	}
+
+	if !canUseReplyTemplate(user, template) {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "reply template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ReplyTemplateResponse{Data: replyTemplateResponse(template)})
+}
+
+type PatchReplyTemplateRequest struct {
+	Name    string `json:"name,omitempty" validate:"omitempty,min=2,max=70"`
+	Content string `json:"content,omitempty" validate:"omitempty,min=10,reply_template"`
+	Shared  *bool  `json:"shared,omitempty"`
+}
+
+type PatchReplyTemplateResponse = Response[ReplyTemplate]
+
+func (server *Server) patchReplyTemplate(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	templateId, err := strconv.ParseInt(c.Param("templateId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "reply template not found"})
+		return
+	}
+
+	var req PatchReplyTemplateRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var updatedTemplate sqlc.ReplyTemplate
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		template, err := qtx.GetReplyTemplateByID(ctx, int32(templateId))
+		if err != nil || !canUseReplyTemplate(user, template) {
+			return ReplyTemplateNotFoundError{}
+		}
+
+		if !canManageReplyTemplate(user, template) {
+			return PermissionDeniedError{Message: "only admins can update shared reply templates"}
+		}
+
+		params := sqlc.UpdateReplyTemplateByIDParams{
+			ID:      template.ID,
+			Name:    template.Name,
+			Content: template.Content,
+			Shared:  template.Shared,
+		}
+		if req.Name != "" {
+			params.Name = req.Name
+		}
+		if req.Content != "" {
+			params.Content = req.Content
+		}
+		if req.Shared != nil {
+			if *req.Shared && user.Role != sqlc.RoleAdmin {
+				return PermissionDeniedError{Message: "only admins can share reply templates"}
+			}
+			params.Shared = *req.Shared
+		}
+
+		updatedTemplate, err = qtx.UpdateReplyTemplateByID(ctx, params)
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusOK, PatchReplyTemplateResponse{Data: replyTemplateResponse(updatedTemplate)})
+	case ReplyTemplateNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update reply template"})
+	}
+}
+
+func (server *Server) deleteReplyTemplate(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	templateId, err := strconv.ParseInt(c.Param("templateId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "reply template not found"})
+		return
+	}
+
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		template, err := qtx.GetReplyTemplateByID(ctx, int32(templateId))
+		if err != nil || !canUseReplyTemplate(user, template) {
+			return ReplyTemplateNotFoundError{}
+		}
+
+		if !canManageReplyTemplate(user, template) {
+			return PermissionDeniedError{Message: "only admins can delete shared reply templates"}
+		}
+
+		return qtx.DeleteReplyTemplateByID(ctx, template.ID)
+	})
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case ReplyTemplateNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	case PermissionDeniedError:
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete reply template"})
+	}
+}
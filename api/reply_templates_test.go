@@ -0,0 +1,207 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplyTemplates(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, memberRes := testutil.NewMember(t, &sdk)
+	memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+	var sharedRes api.CreateReplyTemplateResponse
+	httpRes, err := sdk.CreateReplyTemplate(api.CreateReplyTemplateRequest{
+		Name:    "Greeting",
+		Content: "Hi {{requester.name}}, we are looking into \"{{ ticket.title }}\". {{agent.name}}",
+		Shared:  true,
+	}, &sharedRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	require.True(t, sharedRes.Data.Shared)
+
+	var personalRes api.CreateReplyTemplateResponse
+	_, err = memberSdk.CreateReplyTemplate(api.CreateReplyTemplateRequest{
+		Name:    "Closing",
+		Content: "Closing ticket #{{ticket.id}}, reopen it if needed.",
+	}, &personalRes)
+	require.NoError(t, err, "error creating reply template")
+
+	t.Run("success: list shared and own templates", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.ReplyTemplatesResponse
+		_, err := memberSdk.ReplyTemplates(&res)
+		require.NoError(t, err, "error making request")
+		require.Len(t, res.Data, 2)
+
+		// Personal templates are private to their author.
+		_, err = sdk.ReplyTemplates(&res)
+		require.NoError(t, err, "error making request")
+		require.Len(t, res.Data, 1)
+		require.Equal(t, sharedRes.Data.ID, res.Data[0].ID)
+
+		var templateRes api.ReplyTemplateResponse
+		httpRes, err := sdk.ReplyTemplate(personalRes.Data.ID, &templateRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("success: comment from template", func(t *testing.T) {
+		t.Parallel()
+
+		var ticketRes api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       "Printer is on fire",
+			Description: gofakeit.Sentence(10),
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		var commentRes api.CreateCommentResponse
+		httpRes, err := memberSdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			TemplateID: sharedRes.Data.ID,
+		}, &commentRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.Equal(t, "Hi "+setup.Req().Name+", we are looking into \"Printer is on fire\". "+memberRes.Data.Name, commentRes.Data.Content)
+	})
+
+	t.Run("success: update and delete own template", func(t *testing.T) {
+		t.Parallel()
+
+		var createRes api.CreateReplyTemplateResponse
+		_, err := memberSdk.CreateReplyTemplate(api.CreateReplyTemplateRequest{
+			Name:    "Waiting",
+			Content: "Waiting for more information.",
+		}, &createRes)
+		require.NoError(t, err, "error creating reply template")
+
+		var patchRes api.PatchReplyTemplateResponse
+		httpRes, err := memberSdk.PatchReplyTemplate(createRes.Data.ID, api.PatchReplyTemplateRequest{
+			Content: "Waiting for more information from {{requester.name}}.",
+		}, &patchRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Equal(t, "Waiting", patchRes.Data.Name)
+		require.Equal(t, "Waiting for more information from {{requester.name}}.", patchRes.Data.Content)
+
+		httpRes, err = memberSdk.DeleteReplyTemplate(createRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+		var templateRes api.ReplyTemplateResponse
+		httpRes, err = memberSdk.ReplyTemplate(createRes.Data.ID, &templateRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNotFound, httpRes.StatusCode)
+	})
+
+	t.Run("error: unknown variable", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateReplyTemplateResponse
+		httpRes, err := sdk.CreateReplyTemplate(api.CreateReplyTemplateRequest{
+			Name:    "Broken",
+			Content: "Hello {{requester.phone}}",
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "content", "reply_template")
+	})
+
+	t.Run("error: members can't manage shared templates", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateReplyTemplateResponse
+		httpRes, err := memberSdk.CreateReplyTemplate(api.CreateReplyTemplateRequest{
+			Name:    "Shared",
+			Content: "A template for everyone.",
+			Shared:  true,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+
+		httpRes, err = memberSdk.DeleteReplyTemplate(sharedRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+
+	t.Run("error: template of another user", func(t *testing.T) {
+		t.Parallel()
+
+		var ticketRes api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		var res api.CreateCommentResponse
+		httpRes, err := sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			TemplateID: personalRes.Data.ID,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "template_id", "exists")
+	})
+
+	t.Run("error: content and template", func(t *testing.T) {
+		t.Parallel()
+
+		var ticketRes api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		var res api.CreateCommentResponse
+		httpRes, err := sdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			Content:    "Some content next to a template.",
+			TemplateID: sharedRes.Data.ID,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "content", "excluded_with")
+	})
+
+	t.Run("error: template renders too short", func(t *testing.T) {
+		t.Parallel()
+
+		// Another member keeps the template out of the lists checked above.
+		other, _ := testutil.NewMember(t, &sdk)
+		otherSdk := tEnv.AuthSDK(other.Email, other.Password)
+
+		var templateRes api.CreateReplyTemplateResponse
+		_, err := otherSdk.CreateReplyTemplate(api.CreateReplyTemplateRequest{
+			Name:    "Ticket number",
+			Content: "{{ ticket.id }}",
+		}, &templateRes)
+		require.NoError(t, err, "error creating reply template")
+
+		var ticketRes api.CreateTicketResponse
+		_, err = sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		var res api.CreateCommentResponse
+		httpRes, err := otherSdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+			TemplateID: templateRes.Data.ID,
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "template_id", "min")
+	})
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// WorkloadAgeBuckets are the ticket age ranges of the workload report, from
+// the newest to the oldest tickets.
+var WorkloadAgeBuckets = []string{"0-1d", "1-7d", "7-30d", "30d+"}
+
+// WorkloadNoPriority groups tickets without a priority::* label.
+const WorkloadNoPriority = "none"
+
+type Workload struct {
+	User        User `json:"user"`
+	OpenTickets int  `json:"open_tickets"`
+	// ByPriority is keyed by the name of the priority::* label of the
+	// tickets, for example high for priority::high.
+	ByPriority map[string]int `json:"by_priority"`
+	ByAge      map[string]int `json:"by_age"`
+}
+
+type WorkloadResponse = Response[[]Workload]
+
+// workload reports the open tickets assigned to each user. It accepts the
+// same q filters as the ticket search.
+func (server *Server) workload(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role == sqlc.RoleRequester {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins and members can see reports"})
+		return
+	}
+
+	search, ok := parseTicketSearch(user, c.Query("q"))
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "Invalid query",
+			Errors: []ValidationError{
+				{Field: "q", Validator: "search"},
+			},
+		})
+		return
+	}
+
+	rows, err := server.db.Queries().GetWorkload(c, sqlc.GetWorkloadParams{
+		Title:        search.Title,
+		Labels:       search.Labels,
+		LabelScopes:  search.LabelScopes,
+		Assignee:     search.Assignee,
+		AssigneeTeam: search.AssigneeTeam,
+		MemberID:     search.MemberID,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get workload"})
+		return
+	}
+
+	// Rows are sorted by user so each user's rows are contiguous.
+	data := []Workload{}
+	for _, row := range rows {
+		if len(data) == 0 || data[len(data)-1].User.ID != row.User.ID {
+			byAge := make(map[string]int, len(WorkloadAgeBuckets))
+			for _, bucket := range WorkloadAgeBuckets {
+				byAge[bucket] = 0
+			}
+			data = append(data, Workload{
+				User:       userResponse(row.User),
+				ByPriority: map[string]int{},
+				ByAge:      byAge,
+			})
+		}
+
+		priority := row.Priority
+		if priority == "" {
+			priority = WorkloadNoPriority
+		}
+
+		workload := &data[len(data)-1]
+		workload.OpenTickets += int(row.OpenTickets)
+		workload.ByPriority[priority] += int(row.OpenTickets)
+		workload.ByAge[row.AgeBucket] += int(row.OpenTickets)
+	}
+
+	c.JSON(http.StatusOK, WorkloadResponse{Data: data})
+}
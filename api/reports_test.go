@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkload(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	_, memberA := testutil.NewMember(t, &sdk)
+	_, memberB := testutil.NewMember(t, &sdk)
+	for _, name := range []string{"priority::high", "priority::low", "bug"} {
+		sdk.CreateLabel(api.CreateLabelRequest{Name: name}, nil)
+	}
+
+	createTicket := func(assignee int32, labels ...string) api.Ticket {
+		var res api.CreateTicketResponse
+		_, err := sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+			Labels:      labels,
+			AssignedTo:  []int32{assignee},
+		}, &res)
+		require.NoError(t, err, "error creating ticket")
+		return res.Data
+	}
+
+	createTicket(memberA.Data.ID, "priority::high")
+	createTicket(memberA.Data.ID)
+	createTicket(memberB.Data.ID, "priority::low", "bug")
+
+	closed := createTicket(memberA.Data.ID, "priority::high")
+	var statusRes api.PatchTicketStatusResponse
+	_, err := sdk.PatchTicketStatus(closed.ID, api.PatchTicketStatusRequest{Status: "closed"}, &statusRes)
+	require.NoError(t, err, "error closing ticket")
+
+	t.Run("success: open tickets per assignee", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.WorkloadResponse
+		httpRes, err := sdk.Workload(&res, nil)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, res.Data, 2)
+
+		require.Equal(t, memberA.Data.ID, res.Data[0].User.ID)
+		require.Equal(t, 2, res.Data[0].OpenTickets)
+		require.Equal(t, map[string]int{"high": 1, api.WorkloadNoPriority: 1}, res.Data[0].ByPriority)
+		require.Equal(t, 2, res.Data[0].ByAge["0-1d"])
+		require.Equal(t, 0, res.Data[0].ByAge["30d+"])
+
+		require.Equal(t, memberB.Data.ID, res.Data[1].User.ID)
+		require.Equal(t, 1, res.Data[1].OpenTickets)
+		require.Equal(t, map[string]int{"low": 1}, res.Data[1].ByPriority)
+	})
+
+	t.Run("success: search filters", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.WorkloadResponse
+		httpRes, err := sdk.Workload(&res, &url.Values{"q": []string{"label:bug"}})
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, res.Data, 1)
+		require.Equal(t, memberB.Data.ID, res.Data[0].User.ID)
+
+		httpRes, err = sdk.Workload(&res, &url.Values{"q": []string{"label:priority::*"}})
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, res.Data, 2)
+		require.Equal(t, 1, res.Data[0].OpenTickets)
+	})
+
+	t.Run("error: requesters can't see reports", func(t *testing.T) {
+		t.Parallel()
+
+		requester := api.CreateUserRequest{
+			Name:     gofakeit.Name(),
+			Username: gofakeit.Username(),
+			Email:    gofakeit.Email(),
+			Password: testutil.FakePassword(),
+			Role:     "requester",
+		}
+		var userRes api.CreateUserResponse
+		_, err := sdk.CreateUser(requester, &userRes)
+		require.NoError(t, err, "error creating requester")
+		requesterSdk := tEnv.AuthSDK(requester.Email, requester.Password)
+
+		var res api.WorkloadResponse
+		httpRes, err := requesterSdk.Workload(&res, nil)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+type Settings struct {
+	// AutoCreateLabels creates labels that don't exist yet when they are
+	// added to a ticket instead of rejecting the request.
+	AutoCreateLabels bool   `json:"auto_create_labels"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+func settingsResponse(settings sqlc.Setting) Settings {
+	return Settings{
+		AutoCreateLabels: settings.AutoCreateLabels,
+		UpdatedAt:        settings.UpdatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+type SettingsResponse = Response[Settings]
+
+func (server *Server) settings(c *gin.Context) {
+	settings, err := server.db.Queries().GetSettings(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SettingsResponse{Data: settingsResponse(settings)})
+}
+
+type PatchSettingsRequest struct {
+	AutoCreateLabels *bool `json:"auto_create_labels,omitempty"`
+}
+
+type PatchSettingsResponse = Response[Settings]
+
+func (server *Server) patchSettings(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can update settings"})
+		return
+	}
+
+	var req PatchSettingsRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	settings, err := server.db.Queries().GetSettings(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update settings"})
+		return
+	}
+
+	autoCreateLabels := settings.AutoCreateLabels
+	if req.AutoCreateLabels != nil {
+		autoCreateLabels = *req.AutoCreateLabels
+	}
+
+	settings, err = server.db.Queries().UpdateSettings(c, autoCreateLabels)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, PatchSettingsResponse{Data: settingsResponse(settings)})
+}
@@ -0,0 +1,81 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings_UnknownLabels(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	sdk.CreateLabel(api.CreateLabelRequest{Name: "bug"}, nil)
+
+	var settingsRes api.SettingsResponse
+	httpRes, err := sdk.Settings(&settingsRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.False(t, settingsRes.Data.AutoCreateLabels)
+
+	req := api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+		Labels:      []string{"bug", "billing"},
+	}
+
+	var res api.CreateTicketResponse
+	httpRes, err = sdk.CreateTicket(req, &res)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+	testutil.RequireValidationError(t, res.Errors, "labels[1]", "exists")
+
+	var ticketsRes api.TicketsResponse
+	_, err = sdk.Tickets(&ticketsRes, nil)
+	require.NoError(t, err, "error getting tickets")
+	require.Empty(t, ticketsRes.Data)
+
+	autoCreateLabels := true
+	var patchRes api.PatchSettingsResponse
+	httpRes, err = sdk.PatchSettings(api.PatchSettingsRequest{AutoCreateLabels: &autoCreateLabels}, &patchRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusOK, httpRes.StatusCode)
+	require.True(t, patchRes.Data.AutoCreateLabels)
+
+	httpRes, err = sdk.CreateTicket(req, &res)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	require.Equal(t, req.Labels, res.Data.Labels)
+
+	var labelsRes api.LabelsResponse
+	_, err = sdk.Labels(&labelsRes)
+	require.NoError(t, err, "error getting labels")
+	require.Len(t, labelsRes.Data, 2)
+	require.Equal(t, api.DefaultLabelColor, labelsRes.Data[0].Color)
+}
+
+func TestSettings_PatchPermission(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, _ := testutil.NewMember(t, &sdk)
+	memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+	autoCreateLabels := true
+	var res api.PatchSettingsResponse
+	httpRes, err := memberSdk.PatchSettings(api.PatchSettingsRequest{AutoCreateLabels: &autoCreateLabels}, &res)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+}
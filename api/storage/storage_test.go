@@ -0,0 +1,88 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api/storage"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	fakeS3 := testutil.NewFakeS3(t)
+	backends := map[string]storage.Storage{
+		"local": storage.NewLocalStorage(t.TempDir()),
+		"s3":    storage.NewS3Storage(fakeS3.Config()),
+	}
+
+	for name, s := range backends {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			content := []byte("panic: runtime error: invalid memory address")
+
+			err := s.Put(ctx, "1/logs.txt", bytes.NewReader(content), int64(len(content)), "text/plain")
+			require.NoError(t, err, "error putting object")
+
+			r, err := s.Get(ctx, "1/logs.txt")
+			require.NoError(t, err, "error getting object")
+			b, err := io.ReadAll(r)
+			r.Close()
+			require.NoError(t, err, "error reading object")
+			require.Equal(t, content, b)
+
+			err = s.Delete(ctx, "1/logs.txt")
+			require.NoError(t, err, "error deleting object")
+
+			_, err = s.Get(ctx, "1/logs.txt")
+			require.ErrorIs(t, err, storage.ErrNotFound)
+		})
+	}
+
+	t.Run("s3 stores under the bucket", func(t *testing.T) {
+		t.Parallel()
+
+		s := storage.NewS3Storage(fakeS3.Config())
+		err := s.Put(context.Background(), "2/screenshot.png", bytes.NewReader([]byte("png")), 3, "image/png")
+		require.NoError(t, err, "error putting object")
+
+		b, ok := fakeS3.Object("2/screenshot.png")
+		require.True(t, ok, "object should be stored")
+		require.Equal(t, []byte("png"), b)
+	})
+
+	t.Run("s3 signs keys that need escaping", func(t *testing.T) {
+		t.Parallel()
+
+		s := storage.NewS3Storage(fakeS3.Config())
+		err := s.Put(context.Background(), "3/crash report (ü).txt", bytes.NewReader([]byte("txt")), 3, "text/plain")
+		require.NoError(t, err, "error putting object")
+
+		_, ok := fakeS3.Object("3/crash report (ü).txt")
+		require.True(t, ok, "object should be stored")
+	})
+
+	t.Run("s3 rejects invalid signatures", func(t *testing.T) {
+		t.Parallel()
+
+		config := fakeS3.Config()
+		config.SecretAccessKey = "wrong-secret-key"
+		s := storage.NewS3Storage(config)
+		err := s.Put(context.Background(), "4/logs.txt", bytes.NewReader([]byte("txt")), 3, "text/plain")
+		require.ErrorContains(t, err, "403")
+	})
+
+	t.Run("local rejects keys outside the root", func(t *testing.T) {
+		t.Parallel()
+
+		s := storage.NewLocalStorage(t.TempDir())
+		err := s.Put(context.Background(), "../escape", bytes.NewReader(nil), 0, "")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,429 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type Team struct {
+	ID        int32   `json:"id"`
+	Name      string  `json:"name"`
+	Members   []int32 `json:"members"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type TeamsResponse = Response[[]Team]
+
+func (server *Server) teams(c *gin.Context) {
+	teamRows, err := server.db.Queries().GetTeams(c)
+	if err != nil && err != pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get teams"})
+		return
+	}
+
+	teams := make([]Team, len(teamRows))
+	for i, team := range teamRows {
+		teams[i] = Team{
+			ID:        team.ID,
+			Name:      team.Name,
+			Members:   team.Members,
+			CreatedAt: team.CreatedAt.Time.Format(time.RFC3339),
+		}
+	}
+
+	c.JSON(http.StatusOK, TeamsResponse{Data: teams})
+}
+
+type TeamResponse = Response[Team]
+
+func (server *Server) team(c *gin.Context) {
+	teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+		return
+	}
+
+	team, err := server.db.Queries().GetTeamByID(c, int32(teamId))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get team"})
+		return
+	}
+
+	c.JSON(http.StatusOK, TeamResponse{
+		Data: Team{
+			ID:        team.ID,
+			Name:      team.Name,
+			Members:   team.Members,
+			CreatedAt: team.CreatedAt.Time.Format(time.RFC3339),
+		},
+	})
+}
+
+// Team names are used in search queries like "assignee:@billing", so they
+// can't contain the characters used to split the query.
+type CreateTeamRequest struct {
+	Name    string  `json:"name" validate:"required,min=2,max=50,excludesall=0x2C: "`
+	Members []int32 `json:"members,omitempty"`
+}
+
+type CreateTeamResponse = Response[Team]
+
+type TeamNotFoundError struct{}
+
+func (e TeamNotFoundError) Error() string {
+	return "team not found"
+}
+
+// UnknownTeamsError lists the team ids of a request that don't exist as
+// fields like assigned_teams[1].
+type UnknownTeamsError struct {
+	Fields []string
+}
+
+func (e UnknownTeamsError) Error() string {
+	return "unknown teams"
+}
+
+func (e UnknownTeamsError) ValidationErrors() []ValidationError {
+	errors := make([]ValidationError, len(e.Fields))
+	for i, field := range e.Fields {
+		errors[i] = ValidationError{Field: field, Validator: "exists"}
+	}
+	return errors
+}
+
+// ensureTeams checks that teams exist before they are assigned to a ticket.
+// Missing teams are reported as items of field.
+func ensureTeams(ctx context.Context, qtx *sqlc.Queries, field string, teamIDs []int32) error {
+	var unknown UnknownTeamsError
+	for i, teamID := range teamIDs {
+		_, err := qtx.GetTeamByID(ctx, teamID)
+		if err == nil {
+			continue
+		}
+		if err != pgx.ErrNoRows {
+			return err
+		}
+		unknown.Fields = append(unknown.Fields, fmt.Sprintf("%s[%d]", field, i))
+	}
+
+	if len(unknown.Fields) > 0 {
+		return unknown
+	}
+	return nil
+}
+
+type TeamNameAlreadyInUseError struct{}
+
+func (e TeamNameAlreadyInUseError) Error() string {
+	return "team name already in use"
+}
+
+func (server *Server) createTeam(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can create teams"})
+		return
+	}
+
+	var req CreateTeamRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var newTeam sqlc.GetTeamByIDRow
+	err := server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetTeamByName(ctx, req.Name)
+		if err == nil {
+			return TeamNameAlreadyInUseError{}
+		}
+		if err != pgx.ErrNoRows {
+			return err
+		}
+
+		t, err := qtx.CreateTeam(ctx, sqlc.CreateTeamParams{
+			Name:      req.Name,
+			CreatedBy: user.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range req.Members {
+			_, err = qtx.GetUserByID(ctx, userID)
+			if err != nil {
+				return UserNotFoundError{}
+			}
+
+			err = qtx.AddTeamMember(ctx, sqlc.AddTeamMemberParams{
+				TeamID: t.ID,
+				UserID: userID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		newTeam, err = qtx.GetTeamByID(ctx, t.ID)
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateTeamResponse{
+			Data: Team{
+				ID:        newTeam.ID,
+				Name:      newTeam.Name,
+				Members:   newTeam.Members,
+				CreatedAt: newTeam.CreatedAt.Time.Format(time.RFC3339),
+			},
+		})
+	case TeamNameAlreadyInUseError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "name", Validator: "unique"},
+			},
+		})
+	case UserNotFoundError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "members", Validator: "exists"},
+			},
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create team"})
+	}
+}
+
+type PatchTeamRequest struct {
+	Name string `json:"name,omitempty" validate:"omitempty,min=2,max=50,excludesall=0x2C: "`
+}
+
+type PatchTeamResponse = Response[Team]
+
+func (server *Server) patchTeam(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can update teams"})
+		return
+	}
+
+	teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+		return
+	}
+
+	var req PatchTeamRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var updatedTeam sqlc.GetTeamByIDRow
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		team, err := qtx.GetTeamByID(ctx, int32(teamId))
+		if err != nil {
+			return TeamNotFoundError{}
+		}
+
+		if req.Name != "" && req.Name != team.Name {
+			_, err = qtx.GetTeamByName(ctx, req.Name)
+			if err == nil {
+				return TeamNameAlreadyInUseError{}
+			}
+			if err != pgx.ErrNoRows {
+				return err
+			}
+
+			_, err = qtx.UpdateTeamByID(ctx, sqlc.UpdateTeamByIDParams{
+				ID:   team.ID,
+				Name: req.Name,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		updatedTeam, err = qtx.GetTeamByID(ctx, team.ID)
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusOK, PatchTeamResponse{
+			Data: Team{
+				ID:        updatedTeam.ID,
+				Name:      updatedTeam.Name,
+				Members:   updatedTeam.Members,
+				CreatedAt: updatedTeam.CreatedAt.Time.Format(time.RFC3339),
+			},
+		})
+	case TeamNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+	case TeamNameAlreadyInUseError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors: []ValidationError{
+				{Field: "name", Validator: "unique"},
+			},
+		})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update team"})
+	}
+}
+
+func (server *Server) deleteTeam(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can delete teams"})
+		return
+	}
+
+	teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+		return
+	}
+
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetTeamByID(ctx, int32(teamId))
+		if err != nil {
+			return TeamNotFoundError{}
+		}
+
+		return qtx.DeleteTeamByID(ctx, int32(teamId))
+	})
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case TeamNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete team"})
+	}
+}
+
+type AddTeamMemberRequest struct {
+	UserID int32 `json:"user_id" validate:"required,number"`
+}
+
+type AddTeamMemberResponse = Response[Team]
+
+func (server *Server) addTeamMember(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage team members"})
+		return
+	}
+
+	teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+		return
+	}
+
+	var req AddTeamMemberRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var updatedTeam sqlc.GetTeamByIDRow
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetTeamByID(ctx, int32(teamId))
+		if err != nil {
+			return TeamNotFoundError{}
+		}
+
+		_, err = qtx.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			return UserNotFoundError{}
+		}
+
+		err = qtx.AddTeamMember(ctx, sqlc.AddTeamMemberParams{
+			TeamID: int32(teamId),
+			UserID: req.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		updatedTeam, err = qtx.GetTeamByID(ctx, int32(teamId))
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusOK, AddTeamMemberResponse{
+			Data: Team{
+				ID:        updatedTeam.ID,
+				Name:      updatedTeam.Name,
+				Members:   updatedTeam.Members,
+				CreatedAt: updatedTeam.CreatedAt.Time.Format(time.RFC3339),
+			},
+		})
+	case TeamNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+	case UserNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "user not found"})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to add team member"})
+	}
+}
+
+func (server *Server) removeTeamMember(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage team members"})
+		return
+	}
+
+	teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+		return
+	}
+
+	userId, err := strconv.ParseInt(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "user not found"})
+		return
+	}
+
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetTeamByID(ctx, int32(teamId))
+		if err != nil {
+			return TeamNotFoundError{}
+		}
+
+		return qtx.RemoveTeamMember(ctx, sqlc.RemoveTeamMemberParams{
+			TeamID: int32(teamId),
+			UserID: int32(userId),
+		})
+	})
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case TeamNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "team not found"})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to remove team member"})
+	}
+}
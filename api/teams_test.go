@@ -0,0 +1,172 @@
+package api_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Teams(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, memberRes := testutil.NewMember(t, &sdk)
+	memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+	t.Run("success: create team", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateTeamResponse
+		httpRes, err := sdk.CreateTeam(api.CreateTeamRequest{
+			Name:    "on-call",
+			Members: []int32{memberRes.Data.ID},
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.NotEmpty(t, res.Data.ID)
+		require.Equal(t, []int32{memberRes.Data.ID}, res.Data.Members)
+	})
+
+	t.Run("error: duplicated name", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateTeamResponse
+		httpRes, err := sdk.CreateTeam(api.CreateTeamRequest{Name: "support"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+		httpRes, err = sdk.CreateTeam(api.CreateTeamRequest{Name: "support"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "name", "unique")
+	})
+
+	t.Run("error: members can't create teams", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateTeamResponse
+		httpRes, err := memberSdk.CreateTeam(api.CreateTeamRequest{Name: "members"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+
+	t.Run("success: add and remove members", func(t *testing.T) {
+		t.Parallel()
+
+		var teamRes api.CreateTeamResponse
+		_, err := sdk.CreateTeam(api.CreateTeamRequest{Name: "billing"}, &teamRes)
+		require.NoError(t, err, "error creating team")
+
+		var addRes api.AddTeamMemberResponse
+		httpRes, err := sdk.AddTeamMember(teamRes.Data.ID, api.AddTeamMemberRequest{UserID: memberRes.Data.ID}, &addRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Contains(t, addRes.Data.Members, memberRes.Data.ID)
+
+		httpRes, err = sdk.RemoveTeamMember(teamRes.Data.ID, memberRes.Data.ID)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+		var res api.TeamResponse
+		_, err = sdk.Team(teamRes.Data.ID, &res)
+		require.NoError(t, err, "error making request")
+		require.NotContains(t, res.Data.Members, memberRes.Data.ID)
+	})
+
+	t.Run("error: invalid name is not persisted", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateTeamResponse
+		httpRes, err := sdk.CreateTeam(api.CreateTeamRequest{Name: "first:line"}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "name", "excludesall")
+
+		_, err = sdk.CreateTeam(api.CreateTeamRequest{Name: "second-line"}, &res)
+		require.NoError(t, err, "error creating team")
+
+		var patchRes api.PatchTeamResponse
+		httpRes, err = sdk.PatchTeam(res.Data.ID, api.PatchTeamRequest{Name: "second line"}, &patchRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+
+		var teamsRes api.TeamsResponse
+		_, err = sdk.Teams(&teamsRes)
+		require.NoError(t, err, "error listing teams")
+		for _, team := range teamsRes.Data {
+			require.NotEqual(t, "first:line", team.Name)
+			require.NotEqual(t, "second line", team.Name)
+		}
+	})
+}
+
+func TestTickets_FilterByAssignee(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, memberRes := testutil.NewMember(t, &sdk)
+	memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+
+	var teamRes api.CreateTeamResponse
+	httpRes, err := sdk.CreateTeam(api.CreateTeamRequest{
+		Name:    "billing",
+		Members: []int32{memberRes.Data.ID},
+	}, &teamRes)
+	require.NoError(t, err, "error creating team")
+	require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+
+	createTicket := func(req api.CreateTicketRequest) {
+		req.Title = gofakeit.JobTitle()
+		req.Description = gofakeit.Sentence(10)
+		var res api.CreateTicketResponse
+		httpRes, err := sdk.CreateTicket(req, &res)
+		require.NoError(t, err, "error on create ticket request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+	}
+
+	createTicket(api.CreateTicketRequest{AssignedTeams: []int32{teamRes.Data.ID}})
+	createTicket(api.CreateTicketRequest{AssignedTeams: []int32{teamRes.Data.ID}})
+	createTicket(api.CreateTicketRequest{AssignedTo: []int32{memberRes.Data.ID}})
+	createTicket(api.CreateTicketRequest{})
+
+	t.Run("by team", func(t *testing.T) {
+		urlValues := url.Values{"q": []string{"assignee:@billing"}}
+		var res api.TicketsResponse
+		httpRes, err := sdk.Tickets(&res, &urlValues)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, res.Data, 2)
+		require.Equal(t, []int32{teamRes.Data.ID}, res.Data[0].AssignedTeams)
+	})
+
+	t.Run("by username", func(t *testing.T) {
+		urlValues := url.Values{"q": []string{"assignee:" + member.Username}}
+		var res api.TicketsResponse
+		httpRes, err := sdk.Tickets(&res, &urlValues)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, res.Data, 1)
+	})
+
+	t.Run("my work includes team tickets", func(t *testing.T) {
+		urlValues := url.Values{"q": []string{"assignee:me"}}
+		var res api.TicketsResponse
+		httpRes, err := memberSdk.Tickets(&res, &urlValues)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, httpRes.StatusCode)
+		require.Len(t, res.Data, 3)
+	})
+}
@@ -0,0 +1,156 @@
+package testutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api/storage"
+)
+
+const (
+	fakeS3Bucket          = "openticket"
+	fakeS3Region          = "us-east-1"
+	fakeS3AccessKeyID     = "fake-access-key"
+	fakeS3SecretAccessKey = "fake-secret-key"
+)
+
+// FakeS3 is an in-memory S3-compatible server that understands the path-style
+// PUT, GET and DELETE object requests made by storage.S3Storage. Requests are
+// rejected unless their AWS Signature Version 4 matches the fake credentials.
+type FakeS3 struct {
+	server  *httptest.Server
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func NewFakeS3(t *testing.T) *FakeS3 {
+	f := &FakeS3{objects: map[string][]byte{}}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *FakeS3) Config() storage.S3Config {
+	return storage.S3Config{
+		Endpoint:        f.server.URL,
+		Region:          fakeS3Region,
+		Bucket:          fakeS3Bucket,
+		AccessKeyID:     fakeS3AccessKeyID,
+		SecretAccessKey: fakeS3SecretAccessKey,
+	}
+}
+
+func (f *FakeS3) Object(key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.objects[key]
+	return b, ok
+}
+
+func (f *FakeS3) handle(w http.ResponseWriter, r *http.Request) {
+	if !validSignature(r) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+fakeS3Bucket+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = b
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		b, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(b)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// validSignature recomputes the SigV4 signature of r from the fake
+// credentials, following
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+func validSignature(r *http.Request) bool {
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return false
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(auth, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		params[name] = value
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) < 8 {
+		return false
+	}
+	scope := amzDate[:8] + "/" + fakeS3Region + "/s3/aws4_request"
+	if params["Credential"] != fakeS3AccessKeyID+"/"+scope {
+		return false
+	}
+
+	signedHeaders := strings.Split(params["SignedHeaders"], ";")
+	if !slices.IsSorted(signedHeaders) || !slices.Contains(signedHeaders, "host") {
+		return false
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.RawQuery,
+		canonicalHeaders.String(),
+		params["SignedHeaders"],
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + fakeS3SecretAccessKey)
+	for _, data := range []string{amzDate[:8], fakeS3Region, "s3", "aws4_request", stringToSign} {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		key = h.Sum(nil)
+	}
+	return hmac.Equal([]byte(hex.EncodeToString(key)), []byte(params["Signature"]))
+}
@@ -0,0 +1,162 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BrunoQuaresma/openticket/api/mailer"
+)
+
+type FakeSMTPMessage struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Parse parses the captured message as RFC 5322.
+func (m FakeSMTPMessage) Parse(t *testing.T) *mail.Message {
+	msg, err := mail.ReadMessage(bytes.NewReader(m.Data))
+	if err != nil {
+		t.Fatal("error parsing captured message: " + err.Error())
+	}
+	return msg
+}
+
+// FakeSMTP is an in-process SMTP server that captures every message it
+// receives. It only implements the commands used by net/smtp.
+type FakeSMTP struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []FakeSMTPMessage
+	failures int
+}
+
+func NewFakeSMTP(t *testing.T) *FakeSMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("error starting fake smtp server: " + err.Error())
+	}
+
+	f := &FakeSMTP{listener: listener}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+func (f *FakeSMTP) Config() mailer.Config {
+	addr := f.listener.Addr().(*net.TCPAddr)
+	return mailer.Config{
+		Host:         addr.IP.String(),
+		Port:         addr.Port,
+		From:         "Openticket <support@openticket.test>",
+		BaseURL:      "http://openticket.test",
+		RetryBackoff: 100 * time.Millisecond,
+	}
+}
+
+// Messages returns a copy of the messages captured so far.
+func (f *FakeSMTP) Messages() []FakeSMTPMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeSMTPMessage{}, f.messages...)
+}
+
+// FailNext makes the next n messages be rejected with a temporary error.
+func (f *FakeSMTP) FailNext(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures = n
+}
+
+func (f *FakeSMTP) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *FakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(code int, text string) {
+		conn.Write([]byte(strconv.Itoa(code) + " " + text + "\r\n"))
+	}
+
+	var msg FakeSMTPMessage
+	reply(220, "openticket.test ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply(250, "openticket.test")
+		case "MAIL":
+			msg = FakeSMTPMessage{From: addressArg(arg)}
+			reply(250, "OK")
+		case "RCPT":
+			msg.To = append(msg.To, addressArg(arg))
+			reply(250, "OK")
+		case "DATA":
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+			var data bytes.Buffer
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.Data = data.Bytes()
+
+			f.mu.Lock()
+			failed := f.failures > 0
+			if failed {
+				f.failures--
+			} else {
+				f.messages = append(f.messages, msg)
+			}
+			f.mu.Unlock()
+
+			if failed {
+				reply(451, "Try again later")
+			} else {
+				reply(250, "OK")
+			}
+		case "RSET":
+			msg = FakeSMTPMessage{}
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+func addressArg(arg string) string {
+	_, address, _ := strings.Cut(arg, ":")
+	address = strings.TrimSpace(address)
+	address, _, _ = strings.Cut(address, " ")
+	return strings.Trim(address, "<>")
+}
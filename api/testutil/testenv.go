@@ -8,12 +8,14 @@ import (
 
 	"github.com/BrunoQuaresma/openticket/api"
 	"github.com/BrunoQuaresma/openticket/api/database"
+	"github.com/BrunoQuaresma/openticket/api/storage"
 	"github.com/BrunoQuaresma/openticket/sdk"
 	"github.com/brianvoe/gofakeit"
 )
 
 type TestEnv struct {
 	localDatabase *database.LocalDatabase
+	db            *database.Connection
 	server        *api.Server
 	t             *testing.T
 }
@@ -36,7 +38,9 @@ func NewEnv(t *testing.T) TestEnv {
 	if err != nil {
 		t.Fatal("error getting free port for server: " + err.Error())
 	}
-	tEnv.server = api.NewServer(serverPort, &db, api.TestMode)
+	tEnv.db = &db
+	tEnv.server = api.NewServer(serverPort, tEnv.db, api.TestMode)
+	tEnv.server.SetStorage(storage.NewLocalStorage(t.TempDir()))
 
 	return tEnv
 }
@@ -46,7 +50,7 @@ func (tEnv *TestEnv) Start() {
 	if err != nil {
 		tEnv.t.Fatal("error starting test database: " + err.Error())
 	}
-	err = tEnv.localDatabase.Migrate()
+	err = tEnv.db.Migrate()
 	if err != nil {
 		tEnv.t.Fatal("error migrating test database: " + err.Error())
 	}
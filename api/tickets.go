@@ -2,7 +2,6 @@ package api
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"slices"
 	"strconv"
@@ -12,23 +11,28 @@ import (
 	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type CreateTicketRequest struct {
-	Title       string   `json:"title" validate:"required,min=3,max=70"`
-	Description string   `json:"description" validate:"required,min=10"`
-	Labels      []string `json:"labels,omitempty"`
-	AssignedTo  []int32  `json:"assigned_to,omitempty"`
+	Title         string   `json:"title" validate:"required,min=3,max=70"`
+	Description   string   `json:"description" validate:"required,min=10"`
+	Labels        []string `json:"labels,omitempty" validate:"dive,min=1,max=50"`
+	AssignedTo    []int32  `json:"assigned_to,omitempty"`
+	AssignedTeams []int32  `json:"assigned_teams,omitempty"`
 }
 
 type Ticket struct {
-	ID         int32    `json:"id"`
-	Title      string   `json:"title"`
-	Status     string   `json:"status"`
-	Labels     []string `json:"labels"`
-	AssignedTo []int32  `json:"assigned_to"`
-	CreatedBy  User     `json:"created_by"`
-	CreatedAt  string   `json:"created_at"`
+	ID            int32      `json:"id"`
+	Title         string     `json:"title"`
+	Status        string     `json:"status"`
+	Labels        []string   `json:"labels"`
+	AssignedTo    []int32    `json:"assigned_to"`
+	AssignedTeams []int32    `json:"assigned_teams"`
+	Watchers      []int32    `json:"watchers"`
+	Reactions     []Reaction `json:"reactions,omitempty"`
+	CreatedBy     User       `json:"created_by"`
+	CreatedAt     string     `json:"created_at"`
 }
 
 type CreateTicketResponse = Response[Ticket]
@@ -38,140 +42,329 @@ func (server *Server) createTicket(c *gin.Context) {
 
 	var req CreateTicketRequest
 	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
 
-	var (
-		newTicket sqlc.GetTicketByIDRow
-		err       error
-	)
-	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
-		t, err := qtx.CreateTicket(ctx, sqlc.CreateTicketParams{
-			Title:     req.Title,
-			CreatedBy: user.ID,
+	var newTicket sqlc.GetTicketByIDRow
+	err := server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		var err error
+		newTicket, _, err = insertTicket(ctx, qtx, user, req)
+		return err
+	})
+
+	switch err := err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateTicketResponse{Data: ticketResponse(newTicket)})
+	case UnknownLabelsError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors:  err.ValidationErrors(),
 		})
+	case UnknownTeamsError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors:  err.ValidationErrors(),
+		})
+	case InvalidAssigneesError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors:  err.Errors,
+		})
+	default:
+		c.AbortWithError(http.StatusInternalServerError, err)
+	}
+}
+
+func ticketResponse(ticket sqlc.GetTicketByIDRow) Ticket {
+	return Ticket{
+		ID:            ticket.ID,
+		Title:         ticket.Title,
+		Status:        string(ticket.Status),
+		Labels:        ticket.Labels,
+		AssignedTo:    ticket.AssignedTo,
+		AssignedTeams: ticket.AssignedTeams,
+		Watchers:      ticket.Watchers,
+		CreatedAt:     ticket.CreatedAt.Time.Format(time.RFC3339),
+		CreatedBy:     userResponse(ticket.User),
+	}
+}
+
+// insertTicket creates a ticket with its description as the first comment.
+// It is shared by every way tickets can be opened.
+func insertTicket(ctx context.Context, qtx *sqlc.Queries, user *sqlc.User, req CreateTicketRequest) (sqlc.GetTicketByIDRow, sqlc.Comment, error) {
+	t, err := qtx.CreateTicket(ctx, sqlc.CreateTicketParams{
+		Title:     req.Title,
+		CreatedBy: user.ID,
+	})
+	if err != nil {
+		return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+	}
+
+	description, err := qtx.CreateComment(ctx, sqlc.CreateCommentParams{
+		Content:  req.Description,
+		TicketID: t.ID,
+		UserID:   user.ID,
+	})
+	if err != nil {
+		return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+	}
+
+	err = watch(ctx, qtx, t.ID, user.ID)
+	if err != nil {
+		return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+	}
+
+	var labels []string
+	if req.Labels != nil {
+		err = ensureLabels(ctx, qtx, user, req.Labels)
 		if err != nil {
-			return err
+			return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
 		}
 
-		_, err = qtx.CreateComment(ctx, sqlc.CreateCommentParams{
-			Content:  req.Description,
-			TicketID: t.ID,
-			UserID:   user.ID,
-		})
+		labels, err = exclusiveLabels(ctx, qtx, req.Labels, nil)
 		if err != nil {
-			return err
+			return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
 		}
 
-		if req.Labels != nil {
-			for _, labelName := range req.Labels {
-				err = qtx.AssignLabelToTicket(ctx, sqlc.AssignLabelToTicketParams{
-					TicketID:  t.ID,
-					LabelName: labelName,
-				})
-				if err != nil {
-					return err
-				}
+		for _, labelName := range labels {
+			err = qtx.AssignLabelToTicket(ctx, sqlc.AssignLabelToTicketParams{
+				TicketID:  t.ID,
+				LabelName: labelName,
+			})
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
 			}
 		}
+	}
 
-		if req.AssignedTo != nil {
-			for _, userID := range req.AssignedTo {
-				a, err := qtx.CreateAssignment(ctx, sqlc.CreateAssignmentParams{
-					TicketID:   t.ID,
-					UserID:     userID,
-					AssignedBy: user.ID,
-				})
-				fmt.Print(a)
-				if err != nil {
-					return err
-				}
+	if len(req.AssignedTo) > 0 {
+		err = ensureAssignees(ctx, qtx, "assigned_to", req.AssignedTo, nil)
+		if err != nil {
+			return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+		}
+
+		var assignedUserIDs []int32
+		for _, userID := range req.AssignedTo {
+			if slices.Contains(assignedUserIDs, userID) {
+				continue
+			}
+			assignedUserIDs = append(assignedUserIDs, userID)
+
+			assignment, err := qtx.CreateAssignment(ctx, sqlc.CreateAssignmentParams{
+				TicketID:   t.ID,
+				UserID:     userID,
+				AssignedBy: user.ID,
+			})
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+			}
+
+			err = publishEvent(ctx, qtx, EventAssignmentCreated, user, assignmentResponse(assignment))
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
 			}
 		}
 
-		newTicket, err = qtx.GetTicketByID(ctx, t.ID)
+		err = watch(ctx, qtx, t.ID, assignedUserIDs...)
 		if err != nil {
-			return err
+			return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
 		}
 
-		return nil
-	})
+		err = notify(ctx, qtx, NotificationEvent{
+			Type:     sqlc.NotificationTypeAssignment,
+			TicketID: t.ID,
+			ActorID:  user.ID,
+		}, assignedUserIDs...)
+		if err != nil {
+			return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+		}
+	} else {
+		ruleID, userID, err := autoAssign(ctx, qtx, labels)
+		if err != nil {
+			return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+		}
 
+		if userID != 0 {
+			assignment, err := qtx.CreateRuleAssignment(ctx, sqlc.CreateRuleAssignmentParams{
+				TicketID:   t.ID,
+				UserID:     userID,
+				AssignedBy: user.ID,
+				RuleID:     pgtype.Int4{Int32: ruleID, Valid: true},
+			})
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+			}
+
+			err = publishEvent(ctx, qtx, EventAssignmentCreated, user, assignmentResponse(assignment))
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+			}
+
+			err = watch(ctx, qtx, t.ID, userID)
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+			}
+
+			err = notify(ctx, qtx, NotificationEvent{
+				Type:     sqlc.NotificationTypeAssignment,
+				TicketID: t.ID,
+				ActorID:  user.ID,
+			}, userID)
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+			}
+		}
+	}
+
+	if req.AssignedTeams != nil {
+		err = ensureTeams(ctx, qtx, "assigned_teams", req.AssignedTeams)
+		if err != nil {
+			return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+		}
+
+		var assignedTeamIDs []int32
+		for _, teamID := range req.AssignedTeams {
+			if slices.Contains(assignedTeamIDs, teamID) {
+				continue
+			}
+			assignedTeamIDs = append(assignedTeamIDs, teamID)
+
+			teamAssignment, err := qtx.CreateTeamAssignment(ctx, sqlc.CreateTeamAssignmentParams{
+				TicketID:   t.ID,
+				TeamID:     teamID,
+				AssignedBy: user.ID,
+			})
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+			}
+
+			err = publishEvent(ctx, qtx, EventTeamAssignmentCreated, user, teamAssignmentResponse(teamAssignment))
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+			}
+
+			err = notifyTeamAssignment(ctx, qtx, t.ID, teamID, user.ID)
+			if err != nil {
+				return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
+			}
+		}
+	}
+
+	ticket, err := qtx.GetTicketByID(ctx, t.ID)
 	if err != nil {
-		c.AbortWithError(http.StatusInternalServerError, err)
-		return
+		return sqlc.GetTicketByIDRow{}, sqlc.Comment{}, err
 	}
 
-	c.JSON(http.StatusCreated, CreateTicketResponse{
-		Data: Ticket{
-			ID:         newTicket.ID,
-			Title:      newTicket.Title,
-			Status:     string(newTicket.Status),
-			Labels:     newTicket.Labels,
-			AssignedTo: newTicket.AssignedTo,
-			CreatedAt:  newTicket.CreatedAt.Time.Format(time.RFC3339),
-			CreatedBy: User{
-				ID:       newTicket.User.ID,
-				Name:     newTicket.User.Name,
-				Username: newTicket.User.Username,
-				Email:    newTicket.User.Email,
-				Role:     string(newTicket.User.Role),
-			},
-		},
-	})
+	err = publishEvent(ctx, qtx, EventTicketCreated, user, ticketResponse(ticket))
+	return ticket, description, err
 }
 
 type TicketsResponse = Response[[]Ticket]
 
+const (
+	TicketsSortCreated   = "created"
+	TicketsSortReactions = "reactions"
+)
+
 type Tag struct {
 	Key    string
 	Values []string
 }
 
-func (server *Server) tickets(c *gin.Context) {
+// ticketSearch holds the filters of a ticket search query like
+// "login label:bug assignee:me".
+type ticketSearch struct {
+	Title        string
+	Labels       []string
+	LabelScopes  []string
+	Assignee     string
+	AssigneeTeam string
+	MemberID     int32
+}
+
+func parseTicketSearch(user *sqlc.User, q string) (ticketSearch, bool) {
+	var search ticketSearch
+	if q == "" {
+		return search, true
+	}
+
 	var tags []Tag
-	q, hasQuery := c.GetQuery("q")
-	if hasQuery {
-		sentences := strings.Split(q, " ")
-		for _, sentence := range sentences {
-			words := strings.Split(sentence, ":")
-			if len(words) == 1 {
-				words = []string{"title", words[0]}
-			} else if len(words) != 2 {
-				c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
-					Message: "Invalid query",
-					Errors: []ValidationError{
-						{Field: "q", Validator: "search"},
-					},
-				})
-				return
-			}
-			key := words[0]
-			values := strings.Split(words[1], ",")
-			tags = append(tags, Tag{
-				Key:    key,
-				Values: values,
-			})
+	for _, sentence := range strings.Split(q, " ") {
+		// Only the first colon separates the key so scoped labels like
+		// label:type::bug keep theirs.
+		key, value, found := strings.Cut(sentence, ":")
+		if !found {
+			key, value = "title", sentence
+		} else if key == "" || value == "" {
+			return search, false
 		}
+		tags = append(tags, Tag{
+			Key:    key,
+			Values: strings.Split(value, ","),
+		})
 	}
 
-	var (
-		title  string
-		labels []string
-	)
-
-	if len(tags) > 0 {
-		for _, tag := range tags {
-			switch tag.Key {
-			case "title":
-				title = tag.Values[0]
-			case "label":
-				labels = tag.Values
+	for _, tag := range tags {
+		switch tag.Key {
+		case "title":
+			search.Title = tag.Values[0]
+		case "label":
+			// type::* matches every label of the type scope.
+			for _, value := range tag.Values {
+				if scope, ok := strings.CutSuffix(value, "::*"); ok {
+					search.LabelScopes = append(search.LabelScopes, scope)
+				} else {
+					search.Labels = append(search.Labels, value)
+				}
+			}
+		case "assignee":
+			// "me" can't clash with a username since they have at least 3
+			// characters. It matches tickets assigned to the user directly
+			// or to any of their teams.
+			switch value := tag.Values[0]; {
+			case value == "me":
+				search.MemberID = user.ID
+			case strings.HasPrefix(value, "@"):
+				search.AssigneeTeam = strings.TrimPrefix(value, "@")
+			default:
+				search.Assignee = value
 			}
 		}
 	}
+	return search, true
+}
+
+func (server *Server) tickets(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	sort := c.DefaultQuery("sort", TicketsSortCreated)
+	if sort != TicketsSortCreated && sort != TicketsSortReactions {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Errors: []ValidationError{{Field: "sort", Validator: "oneof"}},
+		})
+		return
+	}
+
+	search, ok := parseTicketSearch(user, c.Query("q"))
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: "Invalid query",
+			Errors: []ValidationError{
+				{Field: "q", Validator: "search"},
+			},
+		})
+		return
+	}
 
 	ticketRows, err := server.db.Queries().GetTickets(c, sqlc.GetTicketsParams{
-		Labels: labels,
-		Title:  title,
+		Labels:       search.Labels,
+		LabelScopes:  search.LabelScopes,
+		Title:        search.Title,
+		Assignee:     search.Assignee,
+		AssigneeTeam: search.AssigneeTeam,
+		MemberID:     search.MemberID,
+		Sort:         sort,
 	})
 
 	if err != nil {
@@ -179,15 +372,28 @@ func (server *Server) tickets(c *gin.Context) {
 		return
 	}
 
+	ticketIDs := make([]int32, len(ticketRows))
+	for i, ticket := range ticketRows {
+		ticketIDs[i] = ticket.ID
+	}
+	reactions, err := ticketReactions(c, server.db.Queries(), user.ID, ticketIDs...)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
 	tickets := make([]Ticket, len(ticketRows))
 	for i, ticket := range ticketRows {
 		tickets[i] = Ticket{
-			ID:         ticket.ID,
-			Title:      ticket.Title,
-			Status:     string(ticket.Status),
-			Labels:     ticket.Labels,
-			AssignedTo: ticket.AssignedTo,
-			CreatedAt:  ticket.CreatedAt.Time.Format(time.RFC3339),
+			ID:            ticket.ID,
+			Title:         ticket.Title,
+			Status:        string(ticket.Status),
+			Labels:        ticket.Labels,
+			AssignedTo:    ticket.AssignedTo,
+			AssignedTeams: ticket.AssignedTeams,
+			Watchers:      ticket.Watchers,
+			Reactions:     reactions[ticket.ID],
+			CreatedAt:     ticket.CreatedAt.Time.Format(time.RFC3339),
 			CreatedBy: User{
 				ID:       ticket.User.ID,
 				Name:     ticket.User.Name,
@@ -218,21 +424,37 @@ func (server *Server) deleteTicket(c *gin.Context) {
 		return
 	}
 
+	var storageKeys []string
 	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
 		ticket, err := qtx.GetTicketByID(ctx, int32(ticketId))
 		if err != nil {
 			return TicketNotFoundError{}
 		}
 
-		if ticket.CreatedBy == user.ID || user.Role == "admin" {
-			return qtx.DeleteTicketByID(ctx, int32(ticketId))
+		if ticket.CreatedBy != user.ID && user.Role != "admin" {
+			return PermissionDeniedError{Message: "only admins and the ticket's creator can delete tickets"}
+		}
+
+		// The attachment rows are deleted with the ticket and their stored
+		// files once the transaction is committed.
+		storageKeys, err = qtx.GetAttachmentStorageKeysByTicketID(ctx, ticket.ID)
+		if err != nil {
+			return err
 		}
 
-		return PermissionDeniedError{Message: "only admins and the ticket's creator can delete tickets"}
+		err = qtx.DeleteTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return err
+		}
+
+		return publishEvent(ctx, qtx, EventTicketDeleted, user, ticketResponse(ticket))
 	})
 
 	switch err.(type) {
 	case nil:
+		for _, key := range storageKeys {
+			server.storage.Delete(c.Request.Context(), key)
+		}
 		c.Status(http.StatusNoContent)
 	case TicketNotFoundError:
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
@@ -244,10 +466,11 @@ func (server *Server) deleteTicket(c *gin.Context) {
 }
 
 type PatchTicketRequest struct {
-	Title       string   `json:"title,omitempty" validate:"omitempty,min=3,max=70"`
-	Description string   `json:"description,omitempty" validate:"omitempty,min=10"`
-	Labels      []string `json:"labels,omitempty"`
-	AssignedTo  []int32  `json:"assignments,omitempty"`
+	Title         string   `json:"title,omitempty" validate:"omitempty,min=3,max=70"`
+	Description   string   `json:"description,omitempty" validate:"omitempty,min=10"`
+	Labels        []string `json:"labels,omitempty" validate:"dive,min=1,max=50"`
+	AssignedTo    []int32  `json:"assignments,omitempty"`
+	AssignedTeams []int32  `json:"assigned_teams,omitempty"`
 }
 
 type PatchTicketResponse = Response[Ticket]
@@ -263,6 +486,9 @@ func (server *Server) patchTicket(c *gin.Context) {
 
 	var req PatchTicketRequest
 	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
 
 	var (
 		updatedTicket sqlc.GetTicketByIDRow
@@ -283,8 +509,18 @@ func (server *Server) patchTicket(c *gin.Context) {
 		}
 
 		if req.Labels != nil {
+			err = ensureLabels(ctx, qtx, user, req.Labels)
+			if err != nil {
+				return err
+			}
+
+			labels, err := exclusiveLabels(ctx, qtx, req.Labels, ticket.Labels)
+			if err != nil {
+				return err
+			}
+
 			for _, oldLabelName := range ticket.Labels {
-				if !slices.Contains(req.Labels, oldLabelName) {
+				if !slices.Contains(labels, oldLabelName) {
 					err := qtx.UnassignLabelFromTicket(ctx, sqlc.UnassignLabelFromTicketParams{
 						TicketID:  ticket.ID,
 						LabelName: oldLabelName,
@@ -294,7 +530,7 @@ func (server *Server) patchTicket(c *gin.Context) {
 					}
 				}
 			}
-			for _, newLabelName := range req.Labels {
+			for _, newLabelName := range labels {
 				if !slices.Contains(ticket.Labels, newLabelName) {
 					err := qtx.AssignLabelToTicket(ctx, sqlc.AssignLabelToTicketParams{
 						TicketID:  ticket.ID,
@@ -316,11 +552,25 @@ func (server *Server) patchTicket(c *gin.Context) {
 			for i, assignment := range assignments {
 				assignedUserIDs[i] = assignment.UserID
 			}
-			for _, oldUserID := range assignedUserIDs {
-				if !slices.Contains(req.AssignedTo, oldUserID) {
+
+			err = ensureAssignees(ctx, qtx, "assignments", req.AssignedTo, assignedUserIDs)
+			if err != nil {
+				return err
+			}
+			for _, oldAssignment := range assignments {
+				if !slices.Contains(req.AssignedTo, oldAssignment.UserID) {
 					err := qtx.DeleteAssignmentByTicketIDAndUserID(ctx, sqlc.DeleteAssignmentByTicketIDAndUserIDParams{
 						TicketID: ticket.ID,
-						UserID:   int32(oldUserID),
+						UserID:   oldAssignment.UserID,
+					})
+					if err != nil {
+						return err
+					}
+
+					err = publishEvent(ctx, qtx, EventAssignmentDeleted, user, Assignment{
+						ID:       oldAssignment.ID,
+						TicketID: oldAssignment.TicketID,
+						UserID:   oldAssignment.UserID,
 					})
 					if err != nil {
 						return err
@@ -329,7 +579,9 @@ func (server *Server) patchTicket(c *gin.Context) {
 			}
 			for _, newUserID := range req.AssignedTo {
 				if !slices.Contains(assignedUserIDs, newUserID) {
-					_, err := qtx.CreateAssignment(ctx, sqlc.CreateAssignmentParams{
+					assignedUserIDs = append(assignedUserIDs, newUserID)
+
+					assignment, err := qtx.CreateAssignment(ctx, sqlc.CreateAssignmentParams{
 						TicketID:   ticket.ID,
 						UserID:     int32(newUserID),
 						AssignedBy: user.ID,
@@ -337,6 +589,85 @@ func (server *Server) patchTicket(c *gin.Context) {
 					if err != nil {
 						return err
 					}
+
+					err = publishEvent(ctx, qtx, EventAssignmentCreated, user, assignmentResponse(assignment))
+					if err != nil {
+						return err
+					}
+
+					err = watch(ctx, qtx, ticket.ID, newUserID)
+					if err != nil {
+						return err
+					}
+
+					err = notify(ctx, qtx, NotificationEvent{
+						Type:     sqlc.NotificationTypeAssignment,
+						TicketID: ticket.ID,
+						ActorID:  user.ID,
+					}, newUserID)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if req.AssignedTeams != nil {
+			err = ensureTeams(ctx, qtx, "assigned_teams", req.AssignedTeams)
+			if err != nil {
+				return err
+			}
+
+			teamAssignments, err := qtx.GetTeamAssignmentsByTicketID(ctx, ticket.ID)
+			if err != nil {
+				return err
+			}
+			assignedTeamIDs := make([]int32, len(teamAssignments))
+			for i, teamAssignment := range teamAssignments {
+				assignedTeamIDs[i] = teamAssignment.TeamID
+			}
+			for _, oldTeamAssignment := range teamAssignments {
+				if !slices.Contains(req.AssignedTeams, oldTeamAssignment.TeamID) {
+					err := qtx.DeleteTeamAssignmentByTicketIDAndTeamID(ctx, sqlc.DeleteTeamAssignmentByTicketIDAndTeamIDParams{
+						TicketID: ticket.ID,
+						TeamID:   oldTeamAssignment.TeamID,
+					})
+					if err != nil {
+						return err
+					}
+
+					err = publishEvent(ctx, qtx, EventTeamAssignmentDeleted, user, TeamAssignment{
+						ID:       oldTeamAssignment.ID,
+						TicketID: oldTeamAssignment.TicketID,
+						TeamID:   oldTeamAssignment.TeamID,
+					})
+					if err != nil {
+						return err
+					}
+				}
+			}
+			for _, newTeamID := range req.AssignedTeams {
+				if !slices.Contains(assignedTeamIDs, newTeamID) {
+					assignedTeamIDs = append(assignedTeamIDs, newTeamID)
+
+					teamAssignment, err := qtx.CreateTeamAssignment(ctx, sqlc.CreateTeamAssignmentParams{
+						TicketID:   ticket.ID,
+						TeamID:     newTeamID,
+						AssignedBy: user.ID,
+					})
+					if err != nil {
+						return err
+					}
+
+					err = publishEvent(ctx, qtx, EventTeamAssignmentCreated, user, teamAssignmentResponse(teamAssignment))
+					if err != nil {
+						return err
+					}
+
+					err = notifyTeamAssignment(ctx, qtx, ticket.ID, newTeamID, user.ID)
+					if err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -354,6 +685,11 @@ func (server *Server) patchTicket(c *gin.Context) {
 			return err
 		}
 
+		err = publishEvent(ctx, qtx, EventTicketUpdated, user, ticketResponse(updatedTicket))
+		if err != nil {
+			return err
+		}
+
 		createdBy, err = qtx.GetUserByID(ctx, ticket.CreatedBy)
 		return err
 	})
@@ -362,12 +698,14 @@ func (server *Server) patchTicket(c *gin.Context) {
 	case nil:
 		c.JSON(http.StatusOK, PatchTicketResponse{
 			Data: Ticket{
-				ID:         updatedTicket.ID,
-				Title:      updatedTicket.Title,
-				Status:     string(updatedTicket.Status),
-				Labels:     updatedTicket.Labels,
-				AssignedTo: updatedTicket.AssignedTo,
-				CreatedAt:  updatedTicket.CreatedAt.Time.Format(time.RFC3339),
+				ID:            updatedTicket.ID,
+				Title:         updatedTicket.Title,
+				Status:        string(updatedTicket.Status),
+				Labels:        updatedTicket.Labels,
+				AssignedTo:    updatedTicket.AssignedTo,
+				AssignedTeams: updatedTicket.AssignedTeams,
+				Watchers:      updatedTicket.Watchers,
+				CreatedAt:     updatedTicket.CreatedAt.Time.Format(time.RFC3339),
 				CreatedBy: User{
 					ID:       createdBy.ID,
 					Name:     createdBy.Name,
@@ -381,6 +719,21 @@ func (server *Server) patchTicket(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
 	case PermissionDeniedError:
 		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: err.Error()})
+	case UnknownLabelsError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors:  err.(UnknownLabelsError).ValidationErrors(),
+		})
+	case UnknownTeamsError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors:  err.(UnknownTeamsError).ValidationErrors(),
+		})
+	case InvalidAssigneesError:
+		c.AbortWithStatusJSON(http.StatusBadRequest, Response[any]{
+			Message: err.Error(),
+			Errors:  err.(InvalidAssigneesError).Errors,
+		})
 	default:
 		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update ticket"})
 	}
@@ -389,13 +742,18 @@ func (server *Server) patchTicket(c *gin.Context) {
 type TicketResponse = Response[Ticket]
 
 func (server *Server) ticket(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
 	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
 		return
 	}
 
-	var ticketRow sqlc.GetTicketByIDRow
+	var (
+		ticketRow sqlc.GetTicketByIDRow
+		reactions map[int32][]Reaction
+	)
 	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
 		ticket, err := qtx.GetTicketByID(ctx, int32(ticketId))
 		if err != nil {
@@ -403,28 +761,15 @@ func (server *Server) ticket(c *gin.Context) {
 		}
 
 		ticketRow = ticket
-		return nil
+		reactions, err = ticketReactions(ctx, qtx, user.ID, ticket.ID)
+		return err
 	})
 
 	switch err.(type) {
 	case nil:
-		c.JSON(http.StatusOK, PatchTicketResponse{
-			Data: Ticket{
-				ID:         ticketRow.ID,
-				Title:      ticketRow.Title,
-				Status:     string(ticketRow.Status),
-				Labels:     ticketRow.Labels,
-				AssignedTo: ticketRow.AssignedTo,
-				CreatedAt:  ticketRow.CreatedAt.Time.Format(time.RFC3339),
-				CreatedBy: User{
-					ID:       ticketRow.User.ID,
-					Name:     ticketRow.User.Name,
-					Username: ticketRow.User.Username,
-					Email:    ticketRow.User.Email,
-					Role:     string(ticketRow.User.Role),
-				},
-			},
-		})
+		ticket := ticketResponse(ticketRow)
+		ticket.Reactions = reactions[ticket.ID]
+		c.JSON(http.StatusOK, PatchTicketResponse{Data: ticket})
 	case TicketNotFoundError:
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
 	default:
@@ -439,6 +784,8 @@ type PatchTicketStatusRequest struct {
 type PatchTicketStatusResponse = Response[Ticket]
 
 func (server *Server) patchTicketStatus(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
 	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
@@ -448,38 +795,45 @@ func (server *Server) patchTicketStatus(c *gin.Context) {
 	var req PatchTicketStatusRequest
 	server.jsonReq(c, &req)
 
-	_, err = server.db.Queries().UpdateTicketStatusByID(
-		c.Request.Context(),
-		sqlc.UpdateTicketStatusByIDParams{
-			ID:     int32(ticketId),
+	var updatedTicket sqlc.GetTicketByIDRow
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		ticket, err := qtx.GetTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return err
+		}
+
+		_, err = qtx.UpdateTicketStatusByID(ctx, sqlc.UpdateTicketStatusByIDParams{
+			ID:     ticket.ID,
 			Status: sqlc.TicketStatus(req.Status),
-		},
-	)
+		})
+		if err != nil {
+			return err
+		}
+
+		updatedTicket, err = qtx.GetTicketByID(ctx, ticket.ID)
+		if err != nil {
+			return err
+		}
+
+		if ticket.Status == sqlc.TicketStatus(req.Status) {
+			return nil
+		}
+
+		err = publishEvent(ctx, qtx, EventTicketStatusChanged, user, ticketResponse(updatedTicket))
+		if err != nil {
+			return err
+		}
+
+		return notifyWatchers(ctx, qtx, NotificationEvent{
+			Type:     sqlc.NotificationTypeStatusChange,
+			TicketID: ticket.ID,
+			ActorID:  user.ID,
+		}, nil)
+	})
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update ticket status"})
 		return
 	}
-	updatedTicket, err := server.db.Queries().GetTicketByID(c.Request.Context(), int32(ticketId))
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get updated ticket"})
-		return
-	}
 
-	c.JSON(http.StatusOK, PatchTicketStatusResponse{
-		Data: Ticket{
-			ID:         updatedTicket.ID,
-			Title:      updatedTicket.Title,
-			Labels:     updatedTicket.Labels,
-			Status:     string(updatedTicket.Status),
-			AssignedTo: updatedTicket.AssignedTo,
-			CreatedAt:  updatedTicket.CreatedAt.Time.Format(time.RFC3339),
-			CreatedBy: User{
-				ID:       updatedTicket.User.ID,
-				Name:     updatedTicket.User.Name,
-				Username: updatedTicket.User.Username,
-				Email:    updatedTicket.User.Email,
-				Role:     string(updatedTicket.User.Role),
-			},
-		},
-	})
+	c.JSON(http.StatusOK, PatchTicketStatusResponse{Data: ticketResponse(updatedTicket)})
 }
@@ -73,13 +73,15 @@ func TestTickets_Success(t *testing.T) {
 	setup := tEnv.Setup()
 	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
 
+	label := gofakeit.HackerAbbreviation()
+	sdk.CreateLabel(api.CreateLabelRequest{Name: label}, nil)
 	numberOfTickets := 5
 	for i := range numberOfTickets {
 		var res api.CreateTicketResponse
 		httpRes, err := sdk.CreateTicket(api.CreateTicketRequest{
 			Title:       gofakeit.JobTitle(),
 			Description: gofakeit.HackerPhrase(),
-			Labels:      []string{gofakeit.HackerAbbreviation()},
+			Labels:      []string{label},
 		}, &res)
 		require.NoError(t, err, "error on create ticket request")
 		require.Equal(t, http.StatusCreated, httpRes.StatusCode, "error creating ticket "+fmt.Sprint(i))
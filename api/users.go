@@ -16,19 +16,33 @@ type CreateUserRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=15"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
-	Role     string `json:"role" validate:"required,oneof=admin member"`
+	Role     string `json:"role" validate:"required,oneof=admin member requester"`
 }
 
 type User struct {
-	ID       int32  `json:"id"`
-	Name     string `json:"name"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	ID          int32  `json:"id"`
+	Name        string `json:"name"`
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	Deactivated bool   `json:"deactivated,omitempty"`
+	OutOfOffice bool   `json:"out_of_office,omitempty"`
 }
 
 type CreateUserResponse = Response[User]
 
+func userResponse(user sqlc.User) User {
+	return User{
+		ID:          user.ID,
+		Name:        user.Name,
+		Username:    user.Username,
+		Email:       user.Email,
+		Role:        string(user.Role),
+		Deactivated: user.Deactivated,
+		OutOfOffice: user.OutOfOffice,
+	}
+}
+
 func (server *Server) createUser(c *gin.Context) {
 	authUser := server.AuthUserFromContext(c)
 	if authUser.Role != "admin" {
@@ -143,7 +157,11 @@ type PatchUserRequest struct {
 	Name     string `json:"name,omitempty" validate:"omitempty,min=3,max=50"`
 	Username string `json:"username,omitempty" validate:"omitempty,min=3,max=15"`
 	Email    string `json:"email,omitempty" validate:"omitempty,email"`
-	Role     string `json:"role,omitempty" validate:"omitempty,oneof=admin member"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=admin member requester"`
+	// Deactivated users can't be assigned to tickets.
+	Deactivated *bool `json:"deactivated,omitempty"`
+	// Users out of office are skipped by automatic assignment.
+	OutOfOffice *bool `json:"out_of_office,omitempty"`
 }
 
 type PatchUserResponse = Response[User]
@@ -174,11 +192,13 @@ func (server *Server) patchUser(c *gin.Context) {
 		}
 
 		params := sqlc.UpdateUserByIDParams{
-			ID:       u.ID,
-			Name:     u.Name,
-			Username: u.Username,
-			Email:    u.Email,
-			Role:     u.Role,
+			ID:          u.ID,
+			Name:        u.Name,
+			Username:    u.Username,
+			Email:       u.Email,
+			Role:        u.Role,
+			Deactivated: u.Deactivated,
+			OutOfOffice: u.OutOfOffice,
 		}
 
 		if req.Name != "" {
@@ -205,7 +225,7 @@ func (server *Server) patchUser(c *gin.Context) {
 			if authUser.Role != "admin" {
 				return PermissionDeniedError{Message: "only admins can update roles"}
 			}
-			if req.Role == "member" && u.Role == sqlc.RoleAdmin {
+			if req.Role != "admin" && u.Role == sqlc.RoleAdmin {
 				countAdmins, err := qtx.CountAdmins(ctx)
 				if err != nil {
 					return err
@@ -218,6 +238,20 @@ func (server *Server) patchUser(c *gin.Context) {
 			params.Role = sqlc.Role(req.Role)
 		}
 
+		if req.Deactivated != nil && u.Deactivated != *req.Deactivated {
+			if authUser.Role != "admin" {
+				return PermissionDeniedError{Message: "only admins can deactivate users"}
+			}
+			if u.ID == authUser.ID {
+				return PermissionDeniedError{Message: "can't deactivate yourself"}
+			}
+			params.Deactivated = *req.Deactivated
+		}
+
+		if req.OutOfOffice != nil {
+			params.OutOfOffice = *req.OutOfOffice
+		}
+
 		updatedUser, err = qtx.UpdateUserByID(ctx, params)
 		if err != nil {
 			return err
@@ -256,11 +290,13 @@ func (server *Server) patchUser(c *gin.Context) {
 
 	res := PatchUserResponse{
 		Data: User{
-			ID:       updatedUser.ID,
-			Name:     updatedUser.Name,
-			Username: updatedUser.Username,
-			Email:    updatedUser.Email,
-			Role:     string(updatedUser.Role),
+			ID:          updatedUser.ID,
+			Name:        updatedUser.Name,
+			Username:    updatedUser.Username,
+			Email:       updatedUser.Email,
+			Role:        string(updatedUser.Role),
+			Deactivated: updatedUser.Deactivated,
+			OutOfOffice: updatedUser.OutOfOffice,
 		},
 	}
 	c.JSON(http.StatusOK, res)
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// watch subscribes users to a ticket. Users that already watch it are kept as
+// they are.
+func watch(ctx context.Context, qtx *sqlc.Queries, ticketID int32, userIDs ...int32) error {
+	for _, userID := range userIDs {
+		err := qtx.AddTicketWatcher(ctx, sqlc.AddTicketWatcherParams{
+			TicketID: ticketID,
+			UserID:   userID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyWatchers fans the event out to everyone watching the ticket. When
+// include is set, only the watchers it returns true for are notified.
+func notifyWatchers(ctx context.Context, qtx *sqlc.Queries, event NotificationEvent, include func(user sqlc.User) bool) error {
+	watchers, err := qtx.GetTicketWatchers(ctx, event.TicketID)
+	if err != nil {
+		return err
+	}
+
+	var recipientIDs []int32
+	for _, watcher := range watchers {
+		if include == nil || include(watcher.User) {
+			recipientIDs = append(recipientIDs, watcher.User.ID)
+		}
+	}
+	return notify(ctx, qtx, event, recipientIDs...)
+}
+
+func (server *Server) watchTicket(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetTicketByID(ctx, int32(ticketId))
+		if err != nil {
+			return TicketNotFoundError{}
+		}
+
+		return watch(ctx, qtx, int32(ticketId), user.ID)
+	})
+
+	switch err.(type) {
+	case nil:
+		c.Status(http.StatusNoContent)
+	case TicketNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to watch ticket"})
+	}
+}
+
+func (server *Server) unwatchTicket(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+
+	ticketId, err := strconv.ParseInt(c.Param("ticketId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "ticket not found"})
+		return
+	}
+
+	err = server.db.Queries().RemoveTicketWatcher(c, sqlc.RemoveTicketWatcherParams{
+		TicketID: int32(ticketId),
+		UserID:   user.ID,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to unwatch ticket"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
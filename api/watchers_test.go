@@ -0,0 +1,74 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Watchers(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	member, memberRes := testutil.NewMember(t, &sdk)
+	memberSdk := tEnv.AuthSDK(member.Email, member.Password)
+	watcher, watcherRes := testutil.NewMember(t, &sdk)
+	watcherSdk := tEnv.AuthSDK(watcher.Email, watcher.Password)
+
+	var ticketRes api.CreateTicketResponse
+	_, err := sdk.CreateTicket(api.CreateTicketRequest{
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.Sentence(10),
+		AssignedTo:  []int32{memberRes.Data.ID},
+	}, &ticketRes)
+	require.NoError(t, err, "error creating ticket")
+	require.ElementsMatch(t, []int32{setup.Res().Data.ID, memberRes.Data.ID}, ticketRes.Data.Watchers)
+
+	httpRes, err := watcherSdk.WatchTicket(ticketRes.Data.ID)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+	var ticket api.TicketResponse
+	_, err = sdk.Ticket(ticketRes.Data.ID, &ticket)
+	require.NoError(t, err, "error making request")
+	require.Contains(t, ticket.Data.Watchers, watcherRes.Data.ID)
+
+	var commentRes api.CreateCommentResponse
+	_, err = memberSdk.CreateComment(ticketRes.Data.ID, api.CreateCommentRequest{
+		Content: "Found the root cause, deploying a fix.",
+	}, &commentRes)
+	require.NoError(t, err, "error creating comment")
+
+	var notificationsRes api.NotificationsResponse
+	_, err = watcherSdk.Notifications(&notificationsRes, nil)
+	require.NoError(t, err, "error making request")
+	require.Len(t, notificationsRes.Data, 1)
+	require.Equal(t, "comment", notificationsRes.Data[0].Type)
+
+	httpRes, err = watcherSdk.UnwatchTicket(ticketRes.Data.ID)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusNoContent, httpRes.StatusCode)
+
+	var statusRes api.PatchTicketStatusResponse
+	_, err = memberSdk.PatchTicketStatus(ticketRes.Data.ID, api.PatchTicketStatusRequest{Status: "closed"}, &statusRes)
+	require.NoError(t, err, "error updating ticket status")
+	require.NotContains(t, statusRes.Data.Watchers, watcherRes.Data.ID)
+
+	_, err = watcherSdk.Notifications(&notificationsRes, nil)
+	require.NoError(t, err, "error making request")
+	require.Len(t, notificationsRes.Data, 1)
+
+	var adminNotificationsRes api.NotificationsResponse
+	_, err = sdk.Notifications(&adminNotificationsRes, nil)
+	require.NoError(t, err, "error making request")
+	require.Len(t, adminNotificationsRes.Data, 2)
+	require.Equal(t, "status_change", adminNotificationsRes.Data[0].Type)
+}
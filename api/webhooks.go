@@ -0,0 +1,603 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sqlc "github.com/BrunoQuaresma/openticket/api/database/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	webhookPollInterval    = 500 * time.Millisecond
+	webhookBatchSize       = 20
+	webhookMaxResponseBody = 4 << 10
+)
+
+// WebhookEventPing is only sent by the ping endpoint, webhooks can't subscribe
+// to it.
+const WebhookEventPing = "ping"
+
+const (
+	WebhookSignatureHeader = "X-Openticket-Signature"
+	WebhookEventHeader     = "X-Openticket-Event"
+	WebhookDeliveryHeader  = "X-Openticket-Delivery"
+)
+
+const (
+	DefaultWebhookMaxAttempts  = 8
+	DefaultWebhookRetryBackoff = 10 * time.Second
+	DefaultWebhookTimeout      = 10 * time.Second
+)
+
+type WebhookConfig struct {
+	// MaxAttempts is the number of times a delivery is tried before it is
+	// marked as failed.
+	MaxAttempts int
+	// RetryBackoff is the wait before the first retry. It doubles on every
+	// failed attempt.
+	RetryBackoff time.Duration
+	Timeout      time.Duration
+}
+
+func (server *Server) SetWebhookConfig(config WebhookConfig) {
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = DefaultWebhookMaxAttempts
+	}
+	if config.RetryBackoff == 0 {
+		config.RetryBackoff = DefaultWebhookRetryBackoff
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultWebhookTimeout
+	}
+	server.webhookConfig = config
+}
+
+// WebhookSignature returns the value of the signature header sent with a
+// payload. Receivers should compute it with their secret and compare it in
+// constant time.
+func WebhookSignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (server *Server) startWebhookWorker() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	server.stopWebhookWorker = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := server.sendPendingWebhooks(ctx)
+				if err != nil && ctx.Err() == nil {
+					log.Println("error delivering webhooks: " + err.Error())
+				}
+			}
+		}
+	}()
+}
+
+func (server *Server) sendPendingWebhooks(ctx context.Context) error {
+	deliveries, err := server.db.Queries().ClaimWebhookDeliveries(ctx, webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	// Deliveries are sent concurrently so a slow receiver doesn't hold back
+	// the others.
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := server.sendWebhook(ctx, delivery)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func (server *Server) sendWebhook(ctx context.Context, delivery sqlc.ClaimWebhookDeliveriesRow) error {
+	config := server.webhookConfig
+	status, body, err := server.postWebhook(ctx, delivery)
+	if err == nil {
+		return server.db.Queries().MarkWebhookDeliverySucceeded(ctx, sqlc.MarkWebhookDeliverySucceededParams{
+			ID:             delivery.ID,
+			ResponseStatus: pgtype.Int4{Int32: int32(status), Valid: true},
+			ResponseBody:   pgtype.Text{String: body, Valid: true},
+		})
+	}
+
+	backoff := retryBackoff(config.RetryBackoff, delivery.Attempts)
+	return server.db.Queries().MarkWebhookDeliveryFailed(ctx, sqlc.MarkWebhookDeliveryFailedParams{
+		ID:             delivery.ID,
+		MaxAttempts:    int32(config.MaxAttempts),
+		ResponseStatus: pgtype.Int4{Int32: int32(status), Valid: status != 0},
+		ResponseBody:   pgtype.Text{String: body, Valid: status != 0},
+		Error:          err.Error(),
+		BackoffMs:      int32(backoff.Milliseconds()),
+	})
+}
+
+// postWebhook sends a delivery and returns the response status and the start
+// of the response body. Any status other than 2xx is an error.
+func (server *Server) postWebhook(ctx context.Context, delivery sqlc.ClaimWebhookDeliveriesRow) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, server.webhookConfig.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.WebhookUrl, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Openticket-Webhook")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(int(delivery.ID)))
+	req.Header.Set(WebhookSignatureHeader, WebhookSignature(delivery.WebhookSecret, delivery.Payload))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, webhookMaxResponseBody))
+	if err != nil {
+		return res.StatusCode, "", err
+	}
+	body := strings.ReplaceAll(string(bytes.ToValidUTF8(b, nil)), "\x00", "")
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, body, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res.StatusCode, body, nil
+}
+
+type Webhook struct {
+	ID     int32    `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret is only returned when the webhook is created.
+	Secret    string `json:"secret,omitempty"`
+	Active    bool   `json:"active"`
+	CreatedAt string `json:"created_at"`
+}
+
+func webhookResponse(webhook sqlc.Webhook) Webhook {
+	return Webhook{
+		ID:        webhook.ID,
+		URL:       webhook.Url,
+		Events:    webhook.Events,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+type WebhookDelivery struct {
+	ID             int32           `json:"id"`
+	WebhookID      int32           `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	ResponseStatus int32           `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+func webhookDeliveryResponse(delivery sqlc.WebhookDelivery) WebhookDelivery {
+	var deliveredAt string
+	if delivery.DeliveredAt.Valid {
+		deliveredAt = delivery.DeliveredAt.Time.Format(time.RFC3339)
+	}
+	return WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus.Int32,
+		ResponseBody:   delivery.ResponseBody.String,
+		Error:          delivery.Error.String,
+		DeliveredAt:    deliveredAt,
+		CreatedAt:      delivery.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+type WebhookNotFoundError struct{}
+
+func (e WebhookNotFoundError) Error() string {
+	return "webhook not found"
+}
+
+type WebhookDeliveryNotFoundError struct{}
+
+func (e WebhookDeliveryNotFoundError) Error() string {
+	return "webhook delivery not found"
+}
+
+type WebhooksResponse = Response[[]Webhook]
+
+func (server *Server) webhooks(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage webhooks"})
+		return
+	}
+
+	webhooks, err := server.db.Queries().GetWebhooks(c)
+	if err != nil && err != pgx.ErrNoRows {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get webhooks"})
+		return
+	}
+
+	data := make([]Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		data[i] = webhookResponse(webhook)
+	}
+	c.JSON(http.StatusOK, WebhooksResponse{Data: data})
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,http_url"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=ticket.created ticket.updated ticket.status_changed ticket.deleted comment.created comment.updated comment.deleted assignment.created assignment.deleted team_assignment.created team_assignment.deleted label.created label.updated label.deleted"`
+	// Secret is generated when it is empty.
+	Secret string `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+	Active *bool  `json:"active,omitempty"`
+}
+
+type CreateWebhookResponse = Response[Webhook]
+
+func (server *Server) createWebhook(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage webhooks"})
+		return
+	}
+
+	var req CreateWebhookRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		secret, err = webhookSecret()
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create webhook"})
+			return
+		}
+	}
+
+	webhook, err := server.db.Queries().CreateWebhook(c, sqlc.CreateWebhookParams{
+		Url:       req.URL,
+		Events:    req.Events,
+		Secret:    secret,
+		Active:    req.Active == nil || *req.Active,
+		CreatedBy: user.ID,
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to create webhook"})
+		return
+	}
+
+	data := webhookResponse(webhook)
+	data.Secret = webhook.Secret
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Data: data})
+}
+
+func webhookSecret() (string, error) {
+	a, err := secureToken()
+	if err != nil {
+		return "", err
+	}
+	b, err := secureToken()
+	if err != nil {
+		return "", err
+	}
+	return a + b, nil
+}
+
+type WebhookResponse = Response[Webhook]
+
+func (server *Server) webhook(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage webhooks"})
+		return
+	}
+
+	webhookId, err := strconv.ParseInt(c.Param("webhookId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "webhook not found"})
+		return
+	}
+
+	webhook, err := server.db.Queries().GetWebhookByID(c, int32(webhookId))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "webhook not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookResponse{Data: webhookResponse(webhook)})
+}
+
+type PatchWebhookRequest struct {
+	URL    string   `json:"url,omitempty" validate:"omitempty,http_url"`
+	Events []string `json:"events,omitempty" validate:"omitempty,min=1,dive,oneof=ticket.created ticket.updated ticket.status_changed ticket.deleted comment.created comment.updated comment.deleted assignment.created assignment.deleted team_assignment.created team_assignment.deleted label.created label.updated label.deleted"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+type PatchWebhookResponse = Response[Webhook]
+
+func (server *Server) patchWebhook(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage webhooks"})
+		return
+	}
+
+	webhookId, err := strconv.ParseInt(c.Param("webhookId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "webhook not found"})
+		return
+	}
+
+	var req PatchWebhookRequest
+	server.jsonReq(c, &req)
+	if c.IsAborted() {
+		return
+	}
+
+	var updatedWebhook sqlc.Webhook
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		webhook, err := qtx.GetWebhookByID(ctx, int32(webhookId))
+		if err != nil {
+			return WebhookNotFoundError{}
+		}
+
+		params := sqlc.UpdateWebhookByIDParams{
+			ID:     webhook.ID,
+			Url:    webhook.Url,
+			Events: webhook.Events,
+			Secret: webhook.Secret,
+			Active: webhook.Active,
+		}
+		if req.URL != "" {
+			params.Url = req.URL
+		}
+		if req.Events != nil {
+			params.Events = req.Events
+		}
+		if req.Secret != "" {
+			params.Secret = req.Secret
+		}
+		if req.Active != nil {
+			params.Active = *req.Active
+		}
+
+		updatedWebhook, err = qtx.UpdateWebhookByID(ctx, params)
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusOK, PatchWebhookResponse{Data: webhookResponse(updatedWebhook)})
+	case WebhookNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to update webhook"})
+	}
+}
+
+func (server *Server) deleteWebhook(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage webhooks"})
+		return
+	}
+
+	webhookId, err := strconv.ParseInt(c.Param("webhookId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "webhook not found"})
+		return
+	}
+
+	err = server.db.Queries().DeleteWebhookByID(c, int32(webhookId))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to delete webhook"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type WebhookDeliveriesResponse = Response[[]WebhookDelivery]
+
+func (server *Server) webhookDeliveries(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage webhooks"})
+		return
+	}
+
+	webhookId, err := strconv.ParseInt(c.Param("webhookId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "webhook not found"})
+		return
+	}
+
+	var deliveries []sqlc.WebhookDelivery
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		_, err := qtx.GetWebhookByID(ctx, int32(webhookId))
+		if err != nil {
+			return WebhookNotFoundError{}
+		}
+
+		deliveries, err = qtx.GetWebhookDeliveries(ctx, int32(webhookId))
+		if err == pgx.ErrNoRows {
+			return nil
+		}
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		data := make([]WebhookDelivery, len(deliveries))
+		for i, delivery := range deliveries {
+			data[i] = webhookDeliveryResponse(delivery)
+		}
+		c.JSON(http.StatusOK, WebhookDeliveriesResponse{Data: data})
+	case WebhookNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to get webhook deliveries"})
+	}
+}
+
+type CreateWebhookDeliveryResponse = Response[WebhookDelivery]
+
+// redeliverWebhook queues a new delivery with the payload of a previous one.
+// The original delivery is kept in the log.
+func (server *Server) redeliverWebhook(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage webhooks"})
+		return
+	}
+
+	webhookId, err := strconv.ParseInt(c.Param("webhookId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "webhook not found"})
+		return
+	}
+	deliveryId, err := strconv.ParseInt(c.Param("deliveryId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "webhook delivery not found"})
+		return
+	}
+
+	var delivery sqlc.WebhookDelivery
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		original, err := qtx.GetWebhookDeliveryByID(ctx, sqlc.GetWebhookDeliveryByIDParams{
+			ID:        int32(deliveryId),
+			WebhookID: int32(webhookId),
+		})
+		if err != nil {
+			return WebhookDeliveryNotFoundError{}
+		}
+
+		delivery, err = qtx.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			WebhookID: original.WebhookID,
+			Event:     original.Event,
+			Payload:   original.Payload,
+		})
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateWebhookDeliveryResponse{Data: webhookDeliveryResponse(delivery)})
+	case WebhookDeliveryNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to redeliver webhook"})
+	}
+}
+
+// pingWebhook queues a ping event so admins can check the receiver is
+// reachable and verifies signatures.
+func (server *Server) pingWebhook(c *gin.Context) {
+	user := server.AuthUserFromContext(c)
+	if user.Role != "admin" {
+		c.AbortWithStatusJSON(http.StatusForbidden, Response[any]{Message: "only admins can manage webhooks"})
+		return
+	}
+
+	webhookId, err := strconv.ParseInt(c.Param("webhookId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: "webhook not found"})
+		return
+	}
+
+	var delivery sqlc.WebhookDelivery
+	err = server.db.TX(func(ctx context.Context, qtx *sqlc.Queries, _ pgx.Tx) error {
+		webhook, err := qtx.GetWebhookByID(ctx, int32(webhookId))
+		if err != nil {
+			return WebhookNotFoundError{}
+		}
+
+		payload, err := json.Marshal(EventPayload{
+			Event:     WebhookEventPing,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+			Actor:     userResponse(*user),
+			Data:      webhookResponse(webhook),
+		})
+		if err != nil {
+			return err
+		}
+
+		delivery, err = qtx.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			WebhookID: webhook.ID,
+			Event:     WebhookEventPing,
+			Payload:   payload,
+		})
+		return err
+	})
+
+	switch err.(type) {
+	case nil:
+		c.JSON(http.StatusCreated, CreateWebhookDeliveryResponse{Data: webhookDeliveryResponse(delivery)})
+	case WebhookNotFoundError:
+		c.AbortWithStatusJSON(http.StatusNotFound, Response[any]{Message: err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, Response[any]{Message: "failed to ping webhook"})
+	}
+}
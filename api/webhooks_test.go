@@ -0,0 +1,277 @@
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/testutil"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookRequest struct {
+	header  http.Header
+	body    []byte
+	payload api.EventPayload
+}
+
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []webhookRequest
+	failures int
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	r := &webhookReceiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		var payload api.EventPayload
+		json.Unmarshal(body, &payload)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.failures > 0 {
+			r.failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.requests = append(r.requests, webhookRequest{header: req.Header, body: body, payload: payload})
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) Requests(event string) []webhookRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var requests []webhookRequest
+	for _, req := range r.requests {
+		if req.payload.Event == event {
+			requests = append(requests, req)
+		}
+	}
+	return requests
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Server().SetWebhookConfig(api.WebhookConfig{RetryBackoff: 100 * time.Millisecond})
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	t.Run("success: signed delivery", func(t *testing.T) {
+		t.Parallel()
+
+		receiver := newWebhookReceiver(t)
+		var webhookRes api.CreateWebhookResponse
+		httpRes, err := sdk.CreateWebhook(api.CreateWebhookRequest{
+			URL:    receiver.URL,
+			Events: []string{api.EventTicketCreated, api.EventCommentCreated},
+		}, &webhookRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.NotEmpty(t, webhookRes.Data.Secret)
+		require.True(t, webhookRes.Data.Active)
+
+		var ticketRes api.CreateTicketResponse
+		_, err = sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		require.Eventually(t, func() bool {
+			return len(receiver.Requests(api.EventTicketCreated)) > 0
+		}, 10*time.Second, 100*time.Millisecond)
+
+		var req webhookRequest
+		for _, r := range receiver.Requests(api.EventTicketCreated) {
+			if r.payload.Data.(map[string]any)["id"] == float64(ticketRes.Data.ID) {
+				req = r
+			}
+		}
+		require.NotNil(t, req.header, "ticket.created delivery not found")
+		require.Equal(t, api.EventTicketCreated, req.header.Get(api.WebhookEventHeader))
+		require.NotEmpty(t, req.header.Get(api.WebhookDeliveryHeader))
+		require.Equal(t, api.WebhookSignature(webhookRes.Data.Secret, req.body), req.header.Get(api.WebhookSignatureHeader))
+		require.Equal(t, setup.Res().Data.ID, req.payload.Actor.ID)
+
+		var listRes api.WebhooksResponse
+		_, err = sdk.Webhooks(&listRes)
+		require.NoError(t, err, "error listing webhooks")
+		for _, webhook := range listRes.Data {
+			require.Empty(t, webhook.Secret, "secrets are only returned on creation")
+		}
+	})
+
+	t.Run("success: retry with backoff", func(t *testing.T) {
+		t.Parallel()
+
+		receiver := newWebhookReceiver(t)
+		receiver.failures = 2
+		var webhookRes api.CreateWebhookResponse
+		_, err := sdk.CreateWebhook(api.CreateWebhookRequest{
+			URL:    receiver.URL,
+			Events: []string{api.EventLabelCreated},
+		}, &webhookRes)
+		require.NoError(t, err, "error creating webhook")
+
+		var labelRes api.CreateLabelResponse
+		_, err = sdk.CreateLabel(api.CreateLabelRequest{Name: gofakeit.UUID()}, &labelRes)
+		require.NoError(t, err, "error creating label")
+
+		var deliveriesRes api.WebhookDeliveriesResponse
+		require.Eventually(t, func() bool {
+			_, err := sdk.WebhookDeliveries(webhookRes.Data.ID, &deliveriesRes)
+			return err == nil && len(deliveriesRes.Data) == 1 && deliveriesRes.Data[0].Status == "succeeded"
+		}, 10*time.Second, 100*time.Millisecond)
+
+		delivery := deliveriesRes.Data[0]
+		require.Equal(t, api.EventLabelCreated, delivery.Event)
+		require.Equal(t, int32(3), delivery.Attempts)
+		require.Equal(t, int32(http.StatusOK), delivery.ResponseStatus)
+		require.Equal(t, "ok", delivery.ResponseBody)
+		require.Len(t, receiver.Requests(api.EventLabelCreated), 1)
+	})
+
+	t.Run("success: ping and redeliver", func(t *testing.T) {
+		t.Parallel()
+
+		receiver := newWebhookReceiver(t)
+		var webhookRes api.CreateWebhookResponse
+		_, err := sdk.CreateWebhook(api.CreateWebhookRequest{
+			URL:    receiver.URL,
+			Events: []string{api.EventTicketDeleted},
+		}, &webhookRes)
+		require.NoError(t, err, "error creating webhook")
+
+		var pingRes api.CreateWebhookDeliveryResponse
+		httpRes, err := sdk.PingWebhook(webhookRes.Data.ID, &pingRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.Equal(t, api.WebhookEventPing, pingRes.Data.Event)
+
+		require.Eventually(t, func() bool {
+			return len(receiver.Requests(api.WebhookEventPing)) == 1
+		}, 10*time.Second, 100*time.Millisecond)
+
+		var redeliverRes api.CreateWebhookDeliveryResponse
+		httpRes, err = sdk.RedeliverWebhook(webhookRes.Data.ID, pingRes.Data.ID, &redeliverRes)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusCreated, httpRes.StatusCode)
+		require.NotEqual(t, pingRes.Data.ID, redeliverRes.Data.ID)
+
+		require.Eventually(t, func() bool {
+			return len(receiver.Requests(api.WebhookEventPing)) == 2
+		}, 10*time.Second, 100*time.Millisecond)
+		requests := receiver.Requests(api.WebhookEventPing)
+		require.JSONEq(t, string(requests[0].body), string(requests[1].body))
+
+		var deliveriesRes api.WebhookDeliveriesResponse
+		_, err = sdk.WebhookDeliveries(webhookRes.Data.ID, &deliveriesRes)
+		require.NoError(t, err, "error listing deliveries")
+		require.Len(t, deliveriesRes.Data, 2)
+	})
+
+	t.Run("success: inactive webhooks are skipped", func(t *testing.T) {
+		t.Parallel()
+
+		receiver := newWebhookReceiver(t)
+		active := false
+		var webhookRes api.CreateWebhookResponse
+		_, err := sdk.CreateWebhook(api.CreateWebhookRequest{
+			URL:    receiver.URL,
+			Events: []string{api.EventTicketCreated},
+			Active: &active,
+		}, &webhookRes)
+		require.NoError(t, err, "error creating webhook")
+
+		var ticketRes api.CreateTicketResponse
+		_, err = sdk.CreateTicket(api.CreateTicketRequest{
+			Title:       gofakeit.JobTitle(),
+			Description: gofakeit.Sentence(10),
+		}, &ticketRes)
+		require.NoError(t, err, "error creating ticket")
+
+		var deliveriesRes api.WebhookDeliveriesResponse
+		_, err = sdk.WebhookDeliveries(webhookRes.Data.ID, &deliveriesRes)
+		require.NoError(t, err, "error listing deliveries")
+		require.Empty(t, deliveriesRes.Data)
+	})
+
+	t.Run("error: invalid event", func(t *testing.T) {
+		t.Parallel()
+
+		var res api.CreateWebhookResponse
+		httpRes, err := sdk.CreateWebhook(api.CreateWebhookRequest{
+			URL:    "https://example.com/hook",
+			Events: []string{"ticket.exploded"},
+		}, &res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+		testutil.RequireValidationError(t, res.Errors, "events[0]", "oneof")
+	})
+
+	t.Run("error: member", func(t *testing.T) {
+		t.Parallel()
+
+		member, _ := testutil.NewMember(t, &sdk)
+		memberSDK := tEnv.AuthSDK(member.Email, member.Password)
+
+		var res api.WebhooksResponse
+		httpRes, err := memberSDK.Webhooks(&res)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusForbidden, httpRes.StatusCode)
+	})
+}
+
+func TestWebhooks_InvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	tEnv := testutil.NewEnv(t)
+	tEnv.Start()
+	setup := tEnv.Setup()
+	sdk := tEnv.AuthSDK(setup.Req().Email, setup.Req().Password)
+
+	var res api.CreateWebhookResponse
+	httpRes, err := sdk.CreateWebhook(api.CreateWebhookRequest{
+		URL:    "not a url",
+		Events: []string{"ticket.exploded"},
+	}, &res)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+
+	var webhooksRes api.WebhooksResponse
+	_, err = sdk.Webhooks(&webhooksRes)
+	require.NoError(t, err, "error listing webhooks")
+	require.Empty(t, webhooksRes.Data)
+
+	_, err = sdk.CreateWebhook(api.CreateWebhookRequest{
+		URL:    "https://example.com/hook",
+		Events: []string{"ticket.created"},
+	}, &res)
+	require.NoError(t, err, "error creating webhook")
+
+	var patchRes api.PatchWebhookResponse
+	httpRes, err = sdk.PatchWebhook(res.Data.ID, api.PatchWebhookRequest{
+		URL: "not a url",
+	}, &patchRes)
+	require.NoError(t, err, "error making request")
+	require.Equal(t, http.StatusBadRequest, httpRes.StatusCode)
+
+	var webhookRes api.WebhookResponse
+	_, err = sdk.Webhook(res.Data.ID, &webhookRes)
+	require.NoError(t, err, "error getting webhook")
+	require.Equal(t, "https://example.com/hook", webhookRes.Data.URL)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/BrunoQuaresma/openticket/api/config"
+	"github.com/spf13/cobra"
+)
+
+// configFlags registers the flags shared by commands that load the config.
+// They override the config file and the environment when they are set.
+func configFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("config", "c", "", "Path of a YAML or TOML config file. Env: OPENTICKET_CONFIG.")
+	cmd.Flags().String("data-dir", "", "Directory for the embedded database and attachments. Env: OPENTICKET_DATA_DIR.")
+	cmd.Flags().String("database-url", "", "URL of an external Postgres database. The embedded database is used when empty. Env: OPENTICKET_DATABASE_URL.")
+	cmd.Flags().Uint32("database-port", 0, "Port of the embedded database. Env: OPENTICKET_DATABASE_PORT.")
+}
+
+// loadConfig returns the validated config of the command.
+func loadConfig(cmd *cobra.Command) (config.Config, error) {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		path = os.Getenv("OPENTICKET_CONFIG")
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("data-dir") {
+		cfg.DataDir, _ = flags.GetString("data-dir")
+	}
+	if flags.Changed("database-url") {
+		cfg.Database.URL, _ = flags.GetString("database-url")
+	}
+	if flags.Changed("database-port") {
+		cfg.Database.Port, _ = flags.GetUint32("database-port")
+	}
+	if flags.Changed("listen") {
+		cfg.Listen, _ = flags.GetString("listen")
+	}
+	if flags.Changed("port") {
+		port, _ := flags.GetInt("port")
+		cfg.Listen = fmt.Sprintf(":%d", port)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return config.Config{}, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return cfg, nil
+}
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Print the effective config with secrets redacted.",
+		Long: "Print the effective config with secrets redacted. It is built from " +
+			"the defaults, the config file, OPENTICKET_* environment variables and " +
+			"flags, in that order. Environment variables are named after the keys " +
+			"of the file, for example OPENTICKET_SMTP_HOST.",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+
+			format, _ := cmd.Flags().GetString("format")
+			out, err := cfg.Redact().Marshal(format)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			fmt.Print(string(out))
+		},
+	}
+
+	cmd.Flags().String("format", "yaml", "Output format, yaml or toml.")
+	configFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io"
+
+	"github.com/BrunoQuaresma/openticket/api/config"
+	"github.com/BrunoQuaresma/openticket/api/database"
+)
+
+// connectDatabase connects to the database of cfg, starting the embedded
+// database first when there is no URL. close stops everything it started.
+func connectDatabase(cfg config.Config) (conn database.Connection, close func(), err error) {
+	databaseURL := cfg.Database.URL
+	var localDB *database.LocalDatabase
+	if databaseURL == "" {
+		localDB = localDatabase(cfg)
+		err = localDB.Start()
+		if err != nil {
+			return database.Connection{}, nil, err
+		}
+		databaseURL = localDB.URL()
+	}
+
+	conn, err = database.Connect(databaseURL)
+	if err != nil {
+		if localDB != nil {
+			localDB.Stop()
+		}
+		return database.Connection{}, nil, err
+	}
+
+	return conn, func() {
+		conn.Close()
+		if localDB != nil {
+			localDB.Stop()
+		}
+	}, nil
+}
+
+func localDatabase(cfg config.Config) *database.LocalDatabase {
+	localDB := database.NewLocalDatabase(cfg.Database.Port, cfg.DataDir, io.Discard)
+	localDB.SetCredentials(cfg.Database.Username, cfg.Database.Password, cfg.Database.Name)
+	return localDB
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/BrunoQuaresma/openticket/api/database"
+	"github.com/spf13/cobra"
+)
+
+func migrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Inspect and change the database schema.",
+	}
+
+	cmd.AddCommand(
+		migrateUpCmd(),
+		migrateDownCmd(),
+		migrateStatusCmd(),
+		migrateForceCmd(),
+	)
+
+	return cmd
+}
+
+// withDatabase runs fn with a connection to the database of the command
+// config. The database is closed before exiting on errors so the embedded one
+// isn't left running.
+func withDatabase(cmd *cobra.Command, fn func(conn *database.Connection) error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	conn, close, err := connectDatabase(cfg)
+	if err != nil {
+		log.Fatal("error connecting to database: " + err.Error())
+	}
+
+	err = fn(&conn)
+	close()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+func migrateUpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			withDatabase(cmd, func(conn *database.Connection) error {
+				err := conn.Migrate()
+				if err != nil {
+					return err
+				}
+				return printMigrationStatus(conn)
+			})
+		},
+	}
+	configFlags(cmd)
+	return cmd
+}
+
+func migrateDownCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "down N",
+		Short: "Roll back the last N migrations.",
+		Long: "Roll back the last N migrations. Their data is dropped, so the " +
+			"command is refused unless --yes is given.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			steps, err := strconv.Atoi(args[0])
+			if err != nil || steps < 1 {
+				log.Fatal("N must be a positive number of migrations")
+			}
+			err = requireYes(cmd, "roll back migrations")
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+
+			withDatabase(cmd, func(conn *database.Connection) error {
+				err := conn.MigrateDown(steps)
+				if err != nil {
+					return err
+				}
+				return printMigrationStatus(conn)
+			})
+		},
+	}
+	cmd.Flags().Bool("yes", false, "Confirm rolling back the database.")
+	configFlags(cmd)
+	return cmd
+}
+
+func migrateStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the current and pending migration versions.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			withDatabase(cmd, func(conn *database.Connection) error {
+				return printMigrationStatus(conn)
+			})
+		},
+	}
+	configFlags(cmd)
+	return cmd
+}
+
+func migrateForceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "force V",
+		Short: "Set the migration version without running migrations.",
+		Long: "Set the migration version without running migrations. Use it to " +
+			"clear the dirty state after fixing a failed migration by hand. " +
+			"A version of -1, given after --, marks the database as not migrated. " +
+			"The command is refused unless --yes is given.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			version, err := strconv.Atoi(args[0])
+			if err != nil || version < -1 {
+				log.Fatal("V must be a migration version or -1")
+			}
+			err = requireYes(cmd, "force the migration version")
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+
+			withDatabase(cmd, func(conn *database.Connection) error {
+				err := conn.ForceMigration(version)
+				if err != nil {
+					return err
+				}
+				return printMigrationStatus(conn)
+			})
+		},
+	}
+	cmd.Flags().Bool("yes", false, "Confirm forcing the migration version.")
+	configFlags(cmd)
+	return cmd
+}
+
+// requireYes refuses commands that can lose data unless --yes is given. It is
+// checked before connecting, whichever database backs the config.
+func requireYes(cmd *cobra.Command, action string) error {
+	yes, _ := cmd.Flags().GetBool("yes")
+	if !yes {
+		return fmt.Errorf("refusing to %s without --yes", action)
+	}
+	return nil
+}
+
+func printMigrationStatus(conn *database.Connection) error {
+	status, err := conn.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	version := "none"
+	if status.Version != 0 {
+		version = fmt.Sprint(status.Version)
+	}
+	if status.Dirty {
+		version += " (dirty)"
+	}
+	fmt.Println("Current version: " + version)
+
+	if len(status.Pending) == 0 {
+		fmt.Println("Pending: none")
+		return nil
+	}
+	pending := make([]string, len(status.Pending))
+	for i, v := range status.Pending {
+		pending[i] = fmt.Sprint(v)
+	}
+	fmt.Println("Pending: " + strings.Join(pending, ", "))
+	return nil
+}
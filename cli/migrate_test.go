@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate_RequireYes(t *testing.T) {
+	// Without a database URL the embedded database is used.
+	t.Setenv("OPENTICKET_DATABASE_URL", "")
+
+	for _, cmd := range []*cobra.Command{migrateDownCmd(), migrateForceCmd()} {
+		t.Run(cmd.Name(), func(t *testing.T) {
+			require.NoError(t, cmd.ParseFlags(nil))
+			cfg, err := loadConfig(cmd)
+			require.NoError(t, err)
+			require.Empty(t, cfg.Database.URL)
+			require.ErrorContains(t, requireYes(cmd, "change the database"), "--yes")
+
+			require.NoError(t, cmd.ParseFlags([]string{"--yes"}))
+			require.NoError(t, requireYes(cmd, "change the database"))
+		})
+	}
+}
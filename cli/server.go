@@ -0,0 +1,125 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/BrunoQuaresma/openticket/api"
+	"github.com/BrunoQuaresma/openticket/api/config"
+	"github.com/BrunoQuaresma/openticket/api/mailer"
+	"github.com/BrunoQuaresma/openticket/api/storage"
+	"github.com/briandowns/spinner"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cobra"
+)
+
+func serverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "server",
+		Short: "Start the Openticket server.",
+		Long: "Start the Openticket server. Without a database URL an embedded " +
+			"Postgres is started and its data is kept in the data directory. " +
+			"See the config command for the available settings.",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+
+			if cfg.Log.File != "" {
+				logFile, err := os.OpenFile(cfg.Log.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+				if err != nil {
+					log.Fatal("error opening log file: " + err.Error())
+				}
+				defer logFile.Close()
+				log.SetOutput(logFile)
+				gin.DefaultWriter = logFile
+				gin.DefaultErrorWriter = logFile
+			}
+
+			s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
+
+			// The embedded database is started and stopped along with the
+			// connection.
+			if cfg.Database.URL == "" {
+				loading(s, "Starting local database...", "✔ Local database started")
+			} else {
+				loading(s, "Connecting to the database...", "✔ Database connected")
+			}
+			conn, closeDB, err := connectDatabase(cfg)
+			if err != nil {
+				log.Fatal("error connecting to database: " + err.Error())
+			}
+			defer closeDB()
+			s.Stop()
+
+			loading(s, "Applying migrations...", "✔ Migrations applied")
+			err = conn.Migrate()
+			if err != nil {
+				closeDB()
+				log.Fatal("error migrating database: " + err.Error())
+			}
+			s.Stop()
+
+			mode := api.ProductionMode
+			if cfg.Log.Mode == config.ModeDev {
+				mode = api.DevMode
+			}
+			server := api.NewServer(0, &conn, mode)
+			server.SetAddr(cfg.Listen)
+			server.SetSessionMaxAge(time.Duration(cfg.Sessions.MaxAge))
+			server.SetStorage(newStorage(cfg))
+			if cfg.SMTP.Host != "" {
+				server.SetMailer(mailer.New(mailer.Config{
+					Host:         cfg.SMTP.Host,
+					Port:         cfg.SMTP.Port,
+					Username:     cfg.SMTP.Username,
+					Password:     cfg.SMTP.Password,
+					From:         cfg.SMTP.From,
+					BaseURL:      cfg.SMTP.BaseURL,
+					MaxAttempts:  cfg.SMTP.MaxAttempts,
+					RetryBackoff: time.Duration(cfg.SMTP.RetryBackoff),
+				}))
+			}
+			loading(s, "Starting server...", "✔ Server started on "+server.URL())
+			go func() {
+				defer server.Close()
+				server.Start()
+			}()
+			s.Stop()
+
+			c := make(chan os.Signal, 1)
+			signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+			go func() {
+				<-c
+				server.Close()
+				closeDB()
+				os.Exit(1)
+			}()
+
+			select {}
+		},
+	}
+
+	cmd.Flags().String("listen", "", "Address to run the server on, for example 127.0.0.1:3000. Env: OPENTICKET_LISTEN.")
+	cmd.Flags().IntP("port", "p", 0, "Port to run the server on, listening on all interfaces.")
+	configFlags(cmd)
+
+	return cmd
+}
+
+func newStorage(cfg config.Config) storage.Storage {
+	if cfg.Storage.Driver == config.StorageS3 {
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:        cfg.Storage.S3.Endpoint,
+			Region:          cfg.Storage.S3.Region,
+			Bucket:          cfg.Storage.S3.Bucket,
+			AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+		})
+	}
+	return storage.NewLocalStorage(cfg.AttachmentsDir())
+}
@@ -0,0 +1,23 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) AssignmentRules(res *api.AssignmentRulesResponse) (*http.Response, error) {
+	httpRes, err := c.get("/assignment-rules", res)
+	return httpRes, err
+}
+
+func (c *Client) CreateAssignmentRule(req api.CreateAssignmentRuleRequest, res *api.CreateAssignmentRuleResponse) (*http.Response, error) {
+	httpRes, err := c.post("/assignment-rules", req, res)
+	return httpRes, err
+}
+
+func (c *Client) DeleteAssignmentRule(ruleId int32) (*http.Response, error) {
+	httpRes, err := c.delete("/assignment-rules/" + fmt.Sprint(ruleId))
+	return httpRes, err
+}
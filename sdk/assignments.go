@@ -16,6 +16,19 @@ func (c *Client) CreateAssignment(ticketId int32, userId int32, res *api.CreateA
 	return httpRes, err
 }
 
-func (c *Client) DeleteAssignment(assignmentId int32) (*http.Response, error) {
-	return c.delete("/tickets/1/assignments/" + fmt.Sprint(assignmentId))
+func (c *Client) DeleteAssignment(ticketId int32, assignmentId int32) (*http.Response, error) {
+	return c.delete("/tickets/" + fmt.Sprint(ticketId) + "/assignments/" + fmt.Sprint(assignmentId))
+}
+
+func (c *Client) CreateTeamAssignment(ticketId int32, teamId int32, res *api.CreateTeamAssignmentResponse) (*http.Response, error) {
+	httpRes, err := c.post(
+		"/tickets/"+fmt.Sprint(ticketId)+"/team-assignments",
+		api.CreateTeamAssignmentRequest{TeamID: teamId},
+		res,
+	)
+	return httpRes, err
+}
+
+func (c *Client) DeleteTeamAssignment(ticketId int32, assignmentId int32) (*http.Response, error) {
+	return c.delete("/tickets/" + fmt.Sprint(ticketId) + "/team-assignments/" + fmt.Sprint(assignmentId))
 }
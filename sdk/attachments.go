@@ -0,0 +1,35 @@
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) Attachments(ticketId int32, res *api.AttachmentsResponse) (*http.Response, error) {
+	httpRes, err := c.get("/tickets/"+fmt.Sprint(ticketId)+"/attachments", res)
+	return httpRes, err
+}
+
+func (c *Client) CreateTicketAttachment(ticketId int32, filename string, content io.Reader, res *api.CreateAttachmentResponse) (*http.Response, error) {
+	httpRes, err := c.upload("/tickets/"+fmt.Sprint(ticketId)+"/attachments", filename, content, res)
+	return httpRes, err
+}
+
+func (c *Client) CreateCommentAttachment(ticketId int32, commentId int32, filename string, content io.Reader, res *api.CreateAttachmentResponse) (*http.Response, error) {
+	httpRes, err := c.upload("/tickets/"+fmt.Sprint(ticketId)+"/comments/"+fmt.Sprint(commentId)+"/attachments", filename, content, res)
+	return httpRes, err
+}
+
+// DownloadAttachment returns the raw response so the caller can stream the
+// body. The caller is responsible for closing it.
+func (c *Client) DownloadAttachment(ticketId int32, attachmentId int32) (*http.Response, error) {
+	return c.request("GET", "/tickets/"+fmt.Sprint(ticketId)+"/attachments/"+fmt.Sprint(attachmentId), nil, nil)
+}
+
+func (c *Client) DeleteAttachment(ticketId int32, attachmentId int32) (*http.Response, error) {
+	httpRes, err := c.delete("/tickets/" + fmt.Sprint(ticketId) + "/attachments/" + fmt.Sprint(attachmentId))
+	return httpRes, err
+}
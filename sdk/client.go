@@ -3,6 +3,8 @@ package sdk
 import (
 	"bytes"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"net/http"
 
 	"github.com/BrunoQuaresma/openticket/api"
@@ -71,3 +73,46 @@ func (client *Client) request(method string, path string, req any, res any) (*ht
 	}
 	return httpRes, nil
 }
+
+func (client *Client) upload(path string, filename string, content io.Reader, res any) (*http.Response, error) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(part, content)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.raw(path, w.FormDataContentType(), &b, res)
+}
+
+func (client *Client) raw(path string, contentType string, body io.Reader, res any) (*http.Response, error) {
+	var httpClient http.Client
+	httpReq, err := http.NewRequest("POST", client.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	if client.sessionToken != "" {
+		httpReq.Header.Set(api.TokenHeader, client.sessionToken)
+	}
+	httpRes, err := httpClient.Do(httpReq)
+	if err != nil {
+		return httpRes, err
+	}
+	if res != nil && httpRes.Body != http.NoBody {
+		defer httpRes.Body.Close()
+		err = json.NewDecoder(httpRes.Body).Decode(res)
+		if err != nil {
+			return httpRes, err
+		}
+	}
+	return httpRes, nil
+}
@@ -3,10 +3,20 @@ package sdk
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/BrunoQuaresma/openticket/api"
 )
 
+func (c *Client) Comments(ticketId int32, res *api.CommentsResponse, urlValues *url.Values) (*http.Response, error) {
+	var query string
+	if urlValues != nil {
+		query = "?" + urlValues.Encode()
+	}
+	httpRes, err := c.get("/tickets/"+fmt.Sprint(ticketId)+"/comments"+query, res)
+	return httpRes, err
+}
+
 func (c *Client) CreateComment(ticketId int32, req api.CreateCommentRequest, res *api.CreateCommentResponse) (*http.Response, error) {
 	httpRes, err := c.post("/tickets/"+fmt.Sprint(ticketId)+"/comments", req, res)
 	return httpRes, err
@@ -21,3 +31,8 @@ func (c *Client) PatchComment(ticketId int32, commentId int32, req api.PatchComm
 	httpRes, err := c.patch("/tickets/"+fmt.Sprint(ticketId)+"/comments/"+fmt.Sprint(commentId), req, res)
 	return httpRes, err
 }
+
+func (c *Client) CommentRevisions(ticketId int32, commentId int32, res *api.CommentRevisionsResponse) (*http.Response, error) {
+	httpRes, err := c.get("/tickets/"+fmt.Sprint(ticketId)+"/comments/"+fmt.Sprint(commentId)+"/revisions", res)
+	return httpRes, err
+}
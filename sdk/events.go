@@ -0,0 +1,17 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// EventStream opens the Server-Sent Events stream. Events after lastEventID
+// are replayed when it is not zero. The caller is responsible for closing the
+// body.
+func (c *Client) EventStream(lastEventID int64) (*http.Response, error) {
+	path := "/events/stream"
+	if lastEventID != 0 {
+		path += "?last_event_id=" + fmt.Sprint(lastEventID)
+	}
+	return c.request("GET", path, nil, nil)
+}
@@ -0,0 +1,13 @@
+package sdk
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) InboundEmail(message io.Reader, res *api.InboundEmailResponse) (*http.Response, error) {
+	httpRes, err := c.raw("/inbound/email", "message/rfc822", message, res)
+	return httpRes, err
+}
@@ -0,0 +1,28 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) LabelGroups(res *api.LabelGroupsResponse) (*http.Response, error) {
+	httpRes, err := c.get("/label-groups", res)
+	return httpRes, err
+}
+
+func (c *Client) CreateLabelGroup(req api.CreateLabelGroupRequest, res *api.CreateLabelGroupResponse) (*http.Response, error) {
+	httpRes, err := c.post("/label-groups", req, res)
+	return httpRes, err
+}
+
+func (c *Client) PatchLabelGroup(groupId int, req api.PatchLabelGroupRequest, res *api.PatchLabelGroupResponse) (*http.Response, error) {
+	httpRes, err := c.patch("/label-groups/"+fmt.Sprint(groupId), req, res)
+	return httpRes, err
+}
+
+func (c *Client) DeleteLabelGroup(groupId int) (*http.Response, error) {
+	httpRes, err := c.delete("/label-groups/" + fmt.Sprint(groupId))
+	return httpRes, err
+}
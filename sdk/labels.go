@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/BrunoQuaresma/openticket/api"
@@ -10,3 +11,18 @@ func (c *Client) CreateLabel(req api.CreateLabelRequest, res *api.CreateLabelRes
 	httpRes, err := c.post("/labels", req, res)
 	return httpRes, err
 }
+
+func (c *Client) Labels(res *api.LabelsResponse) (*http.Response, error) {
+	httpRes, err := c.get("/labels", res)
+	return httpRes, err
+}
+
+func (c *Client) PatchLabel(labelId int, req api.PatchLabelRequest, res *api.PatchLabelResponse) (*http.Response, error) {
+	httpRes, err := c.patch("/labels/"+fmt.Sprint(labelId), req, res)
+	return httpRes, err
+}
+
+func (c *Client) DeleteLabel(labelId int) (*http.Response, error) {
+	httpRes, err := c.delete("/labels/" + fmt.Sprint(labelId))
+	return httpRes, err
+}
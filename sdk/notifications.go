@@ -0,0 +1,38 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) Notifications(res *api.NotificationsResponse, urlValues *url.Values) (*http.Response, error) {
+	var query string
+	if urlValues != nil {
+		query = "?" + urlValues.Encode()
+	}
+	httpRes, err := c.get("/notifications"+query, res)
+	return httpRes, err
+}
+
+func (c *Client) MarkNotificationAsRead(notificationId int32) (*http.Response, error) {
+	httpRes, err := c.post("/notifications/"+fmt.Sprint(notificationId)+"/read", nil, nil)
+	return httpRes, err
+}
+
+func (c *Client) MarkAllNotificationsAsRead() (*http.Response, error) {
+	httpRes, err := c.post("/notifications/read-all", nil, nil)
+	return httpRes, err
+}
+
+func (c *Client) NotificationPreferences(res *api.NotificationPreferencesResponse) (*http.Response, error) {
+	httpRes, err := c.get("/notifications/preferences", res)
+	return httpRes, err
+}
+
+func (c *Client) PatchNotificationPreferences(req api.PatchNotificationPreferencesRequest, res *api.NotificationPreferencesResponse) (*http.Response, error) {
+	httpRes, err := c.patch("/notifications/preferences", req, res)
+	return httpRes, err
+}
@@ -0,0 +1,29 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) CreateTicketReaction(ticketId int32, req api.CreateReactionRequest, res *api.ReactionsResponse) (*http.Response, error) {
+	httpRes, err := c.post("/tickets/"+fmt.Sprint(ticketId)+"/reactions", req, res)
+	return httpRes, err
+}
+
+func (c *Client) DeleteTicketReaction(ticketId int32, emoji string) (*http.Response, error) {
+	httpRes, err := c.delete("/tickets/" + fmt.Sprint(ticketId) + "/reactions/" + url.PathEscape(emoji))
+	return httpRes, err
+}
+
+func (c *Client) CreateCommentReaction(ticketId int32, commentId int32, req api.CreateReactionRequest, res *api.ReactionsResponse) (*http.Response, error) {
+	httpRes, err := c.post("/tickets/"+fmt.Sprint(ticketId)+"/comments/"+fmt.Sprint(commentId)+"/reactions", req, res)
+	return httpRes, err
+}
+
+func (c *Client) DeleteCommentReaction(ticketId int32, commentId int32, emoji string) (*http.Response, error) {
+	httpRes, err := c.delete("/tickets/" + fmt.Sprint(ticketId) + "/comments/" + fmt.Sprint(commentId) + "/reactions/" + url.PathEscape(emoji))
+	return httpRes, err
+}
@@ -0,0 +1,33 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) ReplyTemplates(res *api.ReplyTemplatesResponse) (*http.Response, error) {
+	httpRes, err := c.get("/reply-templates", res)
+	return httpRes, err
+}
+
+func (c *Client) CreateReplyTemplate(req api.CreateReplyTemplateRequest, res *api.CreateReplyTemplateResponse) (*http.Response, error) {
+	httpRes, err := c.post("/reply-templates", req, res)
+	return httpRes, err
+}
+
+func (c *Client) ReplyTemplate(templateId int32, res *api.ReplyTemplateResponse) (*http.Response, error) {
+	httpRes, err := c.get("/reply-templates/"+fmt.Sprint(templateId), res)
+	return httpRes, err
+}
+
+func (c *Client) PatchReplyTemplate(templateId int32, req api.PatchReplyTemplateRequest, res *api.PatchReplyTemplateResponse) (*http.Response, error) {
+	httpRes, err := c.patch("/reply-templates/"+fmt.Sprint(templateId), req, res)
+	return httpRes, err
+}
+
+func (c *Client) DeleteReplyTemplate(templateId int32) (*http.Response, error) {
+	httpRes, err := c.delete("/reply-templates/" + fmt.Sprint(templateId))
+	return httpRes, err
+}
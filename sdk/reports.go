@@ -0,0 +1,17 @@
+package sdk
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) Workload(res *api.WorkloadResponse, urlValues *url.Values) (*http.Response, error) {
+	var searchQuery string
+	if urlValues != nil {
+		searchQuery = "?" + urlValues.Encode()
+	}
+	httpRes, err := c.get("/reports/workload"+searchQuery, res)
+	return httpRes, err
+}
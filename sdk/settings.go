@@ -0,0 +1,17 @@
+package sdk
+
+import (
+	"net/http"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) Settings(res *api.SettingsResponse) (*http.Response, error) {
+	httpRes, err := c.get("/settings", res)
+	return httpRes, err
+}
+
+func (c *Client) PatchSettings(req api.PatchSettingsRequest, res *api.PatchSettingsResponse) (*http.Response, error) {
+	httpRes, err := c.patch("/settings", req, res)
+	return httpRes, err
+}
@@ -0,0 +1,43 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) Teams(res *api.TeamsResponse) (*http.Response, error) {
+	httpRes, err := c.get("/teams", res)
+	return httpRes, err
+}
+
+func (c *Client) Team(teamId int32, res *api.TeamResponse) (*http.Response, error) {
+	httpRes, err := c.get("/teams/"+fmt.Sprint(teamId), res)
+	return httpRes, err
+}
+
+func (c *Client) CreateTeam(req api.CreateTeamRequest, res *api.CreateTeamResponse) (*http.Response, error) {
+	httpRes, err := c.post("/teams", req, res)
+	return httpRes, err
+}
+
+func (c *Client) PatchTeam(teamId int32, req api.PatchTeamRequest, res *api.PatchTeamResponse) (*http.Response, error) {
+	httpRes, err := c.patch("/teams/"+fmt.Sprint(teamId), req, res)
+	return httpRes, err
+}
+
+func (c *Client) DeleteTeam(teamId int32) (*http.Response, error) {
+	httpRes, err := c.delete("/teams/" + fmt.Sprint(teamId))
+	return httpRes, err
+}
+
+func (c *Client) AddTeamMember(teamId int32, req api.AddTeamMemberRequest, res *api.AddTeamMemberResponse) (*http.Response, error) {
+	httpRes, err := c.post("/teams/"+fmt.Sprint(teamId)+"/members", req, res)
+	return httpRes, err
+}
+
+func (c *Client) RemoveTeamMember(teamId int32, userId int32) (*http.Response, error) {
+	httpRes, err := c.delete("/teams/" + fmt.Sprint(teamId) + "/members/" + fmt.Sprint(userId))
+	return httpRes, err
+}
@@ -41,3 +41,13 @@ func (c *Client) PatchTicketStatus(ticketId int32, req api.PatchTicketStatusRequ
 	httpRes, err := c.patch("/tickets/"+fmt.Sprint(ticketId)+"/status", req, res)
 	return httpRes, err
 }
+
+func (c *Client) WatchTicket(ticketId int32) (*http.Response, error) {
+	httpRes, err := c.post("/tickets/"+fmt.Sprint(ticketId)+"/watchers", nil, nil)
+	return httpRes, err
+}
+
+func (c *Client) UnwatchTicket(ticketId int32) (*http.Response, error) {
+	httpRes, err := c.delete("/tickets/" + fmt.Sprint(ticketId) + "/watchers")
+	return httpRes, err
+}
@@ -0,0 +1,48 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/BrunoQuaresma/openticket/api"
+)
+
+func (c *Client) Webhooks(res *api.WebhooksResponse) (*http.Response, error) {
+	httpRes, err := c.get("/webhooks", res)
+	return httpRes, err
+}
+
+func (c *Client) Webhook(webhookId int32, res *api.WebhookResponse) (*http.Response, error) {
+	httpRes, err := c.get("/webhooks/"+fmt.Sprint(webhookId), res)
+	return httpRes, err
+}
+
+func (c *Client) CreateWebhook(req api.CreateWebhookRequest, res *api.CreateWebhookResponse) (*http.Response, error) {
+	httpRes, err := c.post("/webhooks", req, res)
+	return httpRes, err
+}
+
+func (c *Client) PatchWebhook(webhookId int32, req api.PatchWebhookRequest, res *api.PatchWebhookResponse) (*http.Response, error) {
+	httpRes, err := c.patch("/webhooks/"+fmt.Sprint(webhookId), req, res)
+	return httpRes, err
+}
+
+func (c *Client) DeleteWebhook(webhookId int32) (*http.Response, error) {
+	httpRes, err := c.delete("/webhooks/" + fmt.Sprint(webhookId))
+	return httpRes, err
+}
+
+func (c *Client) PingWebhook(webhookId int32, res *api.CreateWebhookDeliveryResponse) (*http.Response, error) {
+	httpRes, err := c.post("/webhooks/"+fmt.Sprint(webhookId)+"/ping", nil, res)
+	return httpRes, err
+}
+
+func (c *Client) WebhookDeliveries(webhookId int32, res *api.WebhookDeliveriesResponse) (*http.Response, error) {
+	httpRes, err := c.get("/webhooks/"+fmt.Sprint(webhookId)+"/deliveries", res)
+	return httpRes, err
+}
+
+func (c *Client) RedeliverWebhook(webhookId int32, deliveryId int32, res *api.CreateWebhookDeliveryResponse) (*http.Response, error) {
+	httpRes, err := c.post("/webhooks/"+fmt.Sprint(webhookId)+"/deliveries/"+fmt.Sprint(deliveryId)+"/redeliver", nil, res)
+	return httpRes, err
+}